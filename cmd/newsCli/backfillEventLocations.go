@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+)
+
+// runBackfillEventLocations adds the GeoJSON location used by location-aware trending
+// to the user events stored before it existed.
+// e.g. go run . backfill-event-locations
+func runBackfillEventLocations(args []string) error {
+	flags := flag.NewFlagSet("backfill-event-locations", flag.ExitOnError)
+	envPath := flags.String("env", startup.ENV_DIR, "path to the .env file")
+	flags.Parse(args)
+
+	_, mongoClient, database, logger, err := connect(*envPath)
+	if err != nil {
+		return err
+	}
+	defer startup.Close(mongoClient)
+
+	newsDbInterface := dbInterface.NewNewsDbInterface(database, logger)
+	updated, err := newsDbInterface.BackfillUserEventLocations(context.Background(), constants.USER_EVENT)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added a location to %d user events\n", updated)
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+// runBackfillPlaces reverse geocodes the stored articles into city, region and country code.
+// e.g. go run . backfill-places --batch-size=500 --overwrite
+func runBackfillPlaces(args []string) error {
+	flags := flag.NewFlagSet("backfill-places", flag.ExitOnError)
+	envPath := flags.String("env", startup.ENV_DIR, "path to the .env file")
+	batchSize := flags.Int("batch-size", 500, "number of articles to enrich per batch")
+	overwrite := flags.Bool("overwrite", false, "re-geocode articles that already have a place")
+	flags.Parse(args)
+
+	config, mongoClient, database, logger, err := connect(*envPath)
+	if err != nil {
+		return err
+	}
+	defer startup.Close(mongoClient)
+
+	geocoder, err := utils.NewReverseGeocoder(config.ReverseGeocoder, config.GazetteerPath, config.GazetteerMaxDistanceKm)
+	if err != nil {
+		return err
+	}
+
+	newsDbInterface := dbInterface.NewNewsDbInterface(database, logger)
+	placeService := services.NewPlaceEnrichmentService(newsDbInterface, logger, geocoder)
+
+	enriched, unresolved, err := placeService.BackfillPlaces(context.Background(), *batchSize, *overwrite)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Enriched %d articles, %d could not be resolved\n", enriched, unresolved)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+)
+
+// runBackfillSummaries generates the missing and stale summaries of the stored articles, like the background
+// pre-generation. An interrupted backfill resumes where it stopped when run again with the same category.
+// e.g. go run . backfill-summaries --since=2025-03-01 --category=sports --dry-run
+func runBackfillSummaries(args []string) error {
+	flags := flag.NewFlagSet("backfill-summaries", flag.ExitOnError)
+	envPath := flags.String("env", startup.ENV_DIR, "path to the .env file")
+	since := flags.String("since", "", "only articles published since this date (2025-03-26 or RFC 3339) or for this long (e.g. 168h)")
+	category := flags.String("category", "", "only articles of this category")
+	limit := flags.Int("limit", 0, "most summaries to generate, 0 for all")
+	perMinute := flags.Float64("per-minute", -1, "most summaries generated per minute, 0 for no limit, SUMMARY_PREGEN_PER_MINUTE when not set")
+	dryRun := flags.Bool("dry-run", false, "only count the summaries to generate")
+	flags.Parse(args)
+
+	sinceTime, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	config, mongoClient, database, logger, err := connect(*envPath)
+	if err != nil {
+		return err
+	}
+	defer startup.Close(mongoClient)
+
+	if *perMinute < 0 {
+		*perMinute = config.SummaryPregenPerMinute
+	}
+
+	llmService, err := services.NewLLMProvider(config, logger)
+	if err != nil {
+		return err
+	}
+	newsDbInterface := dbInterface.NewNewsDbInterface(database, logger)
+	apiKeyService := services.NewAPIKeyService(newsDbInterface, logger, config.APIKeyCacheTTL, config.APIKeyRotationGrace)
+	llmService.Usage = services.NewLLMUsageService(newsDbInterface, apiKeyService, logger, config)
+	newsService := services.NewNewsService(newsDbInterface, logger, llmService, config, nil)
+
+	job := "summary-backfill"
+	if *category != "" {
+		job += ":" + *category
+	}
+	result, err := newsService.PregenerateSummaries(context.Background(), services.SummaryBackfillOptions{
+		Job:          job,
+		Since:        sinceTime,
+		Category:     *category,
+		MaxGenerated: *limit,
+		PerMinute:    *perMinute,
+		DryRun:       *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("Checked %d articles, %d summaries to generate, %d failed ones waiting for a retry\n", result.Checked, result.ToGenerate, result.Deferred)
+		return nil
+	}
+	fmt.Printf("Checked %d articles, generated %d summaries, %d failed, %d failed ones waiting for a retry\n", result.Checked, result.Generated, result.Failed, result.Deferred)
+	if !result.Complete {
+		fmt.Println("Stopped before the last article, run again to resume")
+	}
+	return nil
+}
+
+// parseSince parses a date, or a duration back from now; "" is the zero time.
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, since); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("--since must be a date like 2025-03-26, an RFC 3339 time or a duration like 168h")
+}
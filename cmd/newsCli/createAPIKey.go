@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+)
+
+// runCreateAPIKey issues an API key, e.g. the first admin key which then manages the others through the API.
+// e.g. go run . create-api-key --name=ops --scopes=admin --expires-in=720h
+func runCreateAPIKey(args []string) error {
+	flags := flag.NewFlagSet("create-api-key", flag.ExitOnError)
+	envPath := flags.String("env", startup.ENV_DIR, "path to the .env file")
+	name := flags.String("name", "", "name of the client using the key")
+	scopes := flags.String("scopes", newsArticle.SCOPE_READ, "comma separated scopes: read, users:write, events:write, admin")
+	expiresIn := flags.Duration("expires-in", 0, "lifetime of the key, 0 for a key which does not expire")
+	dailyBudget := flags.Float64("daily-budget", -1, "daily LLM budget in USD, 0 for none, the configured default when not set")
+	monthlyBudget := flags.Float64("monthly-budget", -1, "monthly LLM budget in USD, 0 for none, the configured default when not set")
+	flags.Parse(args)
+
+	config, mongoClient, database, logger, err := connect(*envPath)
+	if err != nil {
+		return err
+	}
+	defer startup.Close(mongoClient)
+
+	input := newsArticle.APIKeyInput{Name: *name, Scopes: strings.Split(*scopes, ",")}
+	if *expiresIn > 0 {
+		expiresAt := time.Now().Add(*expiresIn)
+		input.ExpiresAt = &expiresAt
+	}
+	if *dailyBudget >= 0 {
+		input.Budget.DailyUSD = dailyBudget
+	}
+	if *monthlyBudget >= 0 {
+		input.Budget.MonthlyUSD = monthlyBudget
+	}
+
+	newsDbInterface := dbInterface.NewNewsDbInterface(database, logger)
+	apiKeyService := services.NewAPIKeyService(newsDbInterface, logger, config.APIKeyCacheTTL, config.APIKeyRotationGrace)
+	key, err := apiKeyService.CreateAPIKey(context.Background(), input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %s with scopes %s, it is not shown again:\n%s\n", key.ID, strings.Join(key.Scopes, ","), key.Key)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/shivam-cse/contextual-news-api/pkg/logger"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newsCli runs one-off maintenance jobs against the news database.
+// Usage: go run . <command> [flags]
+var commands = map[string]func(args []string) error{
+	"backfill-places":          runBackfillPlaces,
+	"backfill-event-locations": runBackfillEventLocations,
+	"backfill-summaries":       runBackfillSummaries,
+	"create-api-key":           runCreateAPIKey,
+	"sign-token":               runSignToken,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: newsCli <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+	}
+}
+
+// connect loads the configuration and opens the configured database.
+// The caller must close the returned client.
+func connect(envPath string) (*startup.Config, *mongo.Client, *mongo.Database, *slog.Logger, error) {
+	config, err := startup.LoadConfig(envPath)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	mongoClient, err := startup.ConnectMongoDB(config.MongoConnectionString)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return config, mongoClient, mongoClient.Database(config.MongoDatabase), logger.New(), nil
+}
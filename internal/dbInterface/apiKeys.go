@@ -0,0 +1,120 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (newsDbInterface *NewsDbInterface) InsertAPIKey(ctx context.Context, collName string, key newsArticle.APIKey) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Inserting API key...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.InsertOne(ctx, key)
+	return err
+}
+
+// FindAPIKey returns the key with the ID, or nil when there is none.
+func (newsDbInterface *NewsDbInterface) FindAPIKey(ctx context.Context, collName string, id string) (*newsArticle.APIKey, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching API key...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var key newsArticle.APIKey
+	err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindAPIKeys returns all the keys, newest first.
+func (newsDbInterface *NewsDbInterface) FindAPIKeys(ctx context.Context, collName string) ([]newsArticle.APIKey, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching API keys...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}})
+	cursor, err := coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []newsArticle.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RotateAPIKey replaces the secret hash of a key which is not revoked, keeping the previous one valid
+// until 'previousExpiresAt'. It returns the updated key, or nil when there is no such key.
+func (newsDbInterface *NewsDbInterface) RotateAPIKey(
+	ctx context.Context,
+	collName string,
+	id string,
+	hash string,
+	previousExpiresAt time.Time,
+) (*newsArticle.APIKey, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Rotating API key...")
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"previous_hash":       "$hash",
+			"previous_expires_at": previousExpiresAt,
+			"hash":                hash,
+			"rotated_at":          time.Now(),
+		}}},
+	}
+	return newsDbInterface.updateAPIKey(ctx, collName, bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}}, update)
+}
+
+// RevokeAPIKey revokes the key, keeping the time it was first revoked.
+// It returns the updated key, or nil when there is no such key.
+func (newsDbInterface *NewsDbInterface) RevokeAPIKey(ctx context.Context, collName string, id string) (*newsArticle.APIKey, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Revoking API key...")
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", time.Now()}}}}},
+	}
+	return newsDbInterface.updateAPIKey(ctx, collName, bson.M{"_id": id}, update)
+}
+
+// SetAPIKeyBudget replaces the LLM budget of the key. It returns the updated key, or nil when there is no such key.
+func (newsDbInterface *NewsDbInterface) SetAPIKeyBudget(
+	ctx context.Context,
+	collName string,
+	id string,
+	budget newsArticle.LLMBudget,
+) (*newsArticle.APIKey, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Setting API key budget...")
+
+	return newsDbInterface.updateAPIKey(ctx, collName, bson.M{"_id": id}, bson.M{"$set": bson.M{"budget": budget}})
+}
+
+func (newsDbInterface *NewsDbInterface) updateAPIKey(
+	ctx context.Context,
+	collName string,
+	filter bson.M,
+	update interface{},
+) (*newsArticle.APIKey, error) {
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var key newsArticle.APIKey
+	err := coll.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,117 @@
+package dbInterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindSessionArticles returns the distinct articles read in each session since 'since', for the sessions with at least two.
+// Events without a session_id are grouped per user_id instead, and events with neither are ignored.
+// An empty ID counts as missing, so the clients sending one do not all share a session.
+func (newsDbInterface *NewsDbInterface) FindSessionArticles(
+	ctx context.Context,
+	userEventCollName string,
+	eventTypes []string,
+	since time.Time,
+) ([]newsArticle.SessionArticles, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching the articles of user sessions...")
+	coll := newsDbInterface.DB.Collection(userEventCollName)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"timestamp":  bson.M{"$gte": since},
+			"event_type": bson.M{"$in": eventTypes},
+			"$or": bson.A{
+				bson.M{"session_id": bson.M{"$type": "string", "$ne": ""}},
+				bson.M{"user_id": bson.M{"$type": "string", "$ne": ""}},
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{bson.M{"$type": "$session_id"}, "string"}},
+					bson.M{"$ne": bson.A{"$session_id", ""}},
+				}},
+				bson.M{"$concat": bson.A{"s:", "$session_id"}},
+				bson.M{"$concat": bson.A{"u:", "$user_id"}},
+			}},
+			"article_ids": bson.M{"$addToSet": "$article_id"},
+		}}},
+		{{Key: "$match", Value: bson.M{"article_ids.1": bson.M{"$exists": true}}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []newsArticle.SessionArticles
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ReplaceArticleNeighbors upserts the neighbors of every article and deletes the ones of articles
+// which were not part of this build (updated before 'builtAt').
+func (newsDbInterface *NewsDbInterface) ReplaceArticleNeighbors(
+	ctx context.Context,
+	collName string,
+	neighbors []newsArticle.ArticleNeighbors,
+	builtAt time.Time,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Replacing article neighbors...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	if len(neighbors) > 0 {
+		models := make([]mongo.WriteModel, len(neighbors))
+		for i, articleNeighbors := range neighbors {
+			models[i] = mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"_id": articleNeighbors.ArticleID}).
+				SetReplacement(articleNeighbors).
+				SetUpsert(true)
+		}
+		if _, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+
+	_, err := coll.DeleteMany(ctx, bson.M{"updated_at": bson.M{"$lt": builtAt}})
+	return err
+}
+
+// FindArticleNeighbors returns the stored neighbors of the articles, keyed by article ID.
+func (newsDbInterface *NewsDbInterface) FindArticleNeighbors(
+	ctx context.Context,
+	collName string,
+	articleIDs []string,
+) (map[string]newsArticle.ArticleNeighbors, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching article neighbors...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	neighbors := map[string]newsArticle.ArticleNeighbors{}
+	if len(articleIDs) == 0 {
+		return neighbors, nil
+	}
+
+	cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": articleIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []newsArticle.ArticleNeighbors
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, articleNeighbors := range found {
+		neighbors[articleNeighbors.ArticleID] = articleNeighbors
+	}
+	return neighbors, nil
+}
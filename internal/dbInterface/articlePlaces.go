@@ -0,0 +1,78 @@
+package dbInterface
+
+import (
+	"context"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindArticlesForPlaceEnrichment pages through the articles ordered by _id, starting after 'afterID'.
+// Unless 'overwrite' is set, only articles without a country code are returned.
+func (newsDbInterface *NewsDbInterface) FindArticlesForPlaceEnrichment(
+	ctx context.Context,
+	collName string,
+	batchSize int64,
+	afterID string,
+	overwrite bool,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching articles for place enrichment...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{}
+	if afterID != "" {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	if !overwrite {
+		filter["country_code"] = bson.M{"$in": bson.A{nil, ""}}
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"_id": 1, "latitude": 1, "longitude": 1}).
+		SetLimit(batchSize)
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var newsArticles []newsArticle.NewsArticleDBResponse
+	if err := cursor.All(ctx, &newsArticles); err != nil {
+		return nil, err
+	}
+
+	return newsArticles, nil
+}
+
+// UpdateArticlePlaces stores the reverse geocoded place on each article, keyed by article ID.
+func (newsDbInterface *NewsDbInterface) UpdateArticlePlaces(
+	ctx context.Context,
+	collName string,
+	places map[string]utils.Place,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Updating article places...")
+	if len(places) == 0 {
+		return nil
+	}
+	coll := newsDbInterface.DB.Collection(collName)
+
+	models := make([]mongo.WriteModel, 0, len(places))
+	for articleID, place := range places {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": articleID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"city":         place.City,
+				"region":       place.Region,
+				"country_code": place.CountryCode,
+				"place_name":   place.DisplayName(),
+			}}))
+	}
+
+	_, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
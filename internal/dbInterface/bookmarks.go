@@ -0,0 +1,138 @@
+package dbInterface
+
+import (
+	"context"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InsertBookmark stores the bookmark unless the article is already in that collection of the user.
+// It returns the stored bookmark and whether it was created by this call.
+func (newsDbInterface *NewsDbInterface) InsertBookmark(
+	ctx context.Context,
+	collName string,
+	bookmark newsArticle.Bookmark,
+) (*newsArticle.Bookmark, bool, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Inserting bookmark...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	result, err := coll.InsertOne(ctx, bookmark)
+	if err == nil {
+		bookmark.ID = result.InsertedID.(primitive.ObjectID)
+		return &bookmark, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	var existing newsArticle.Bookmark
+	filter := bson.M{"user_id": bookmark.UserID, "collection": bookmark.Collection, "article_id": bookmark.ArticleID}
+	if err := coll.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// DeleteBookmarks removes the article from the user's collection, or from all their collections
+// when 'collection' is empty. It returns the number of removed bookmarks.
+func (newsDbInterface *NewsDbInterface) DeleteBookmarks(
+	ctx context.Context,
+	collName string,
+	userID string,
+	articleID string,
+	collection string,
+) (int64, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Deleting bookmarks...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"user_id": userID, "article_id": articleID}
+	if collection != "" {
+		filter["collection"] = collection
+	}
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// FindBookmarkedArticles returns the user's latest bookmarks, in all collections when 'collection' is empty,
+// each joined with the current data of its article.
+func (newsDbInterface *NewsDbInterface) FindBookmarkedArticles(
+	ctx context.Context,
+	collName string,
+	newsCollName string,
+	userID string,
+	collection string,
+	limit int64,
+) ([]newsArticle.BookmarkedArticle, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching bookmarked articles...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	match := bson.M{"user_id": userID}
+	if collection != "" {
+		match["collection"] = collection
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         newsCollName,
+			"localField":   "article_id",
+			"foreignField": "_id",
+			"as":           "article",
+		}}},
+		{{Key: "$set", Value: bson.M{"article": bson.M{"$first": "$article"}}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bookmarks := []newsArticle.BookmarkedArticle{}
+	if err := cursor.All(ctx, &bookmarks); err != nil {
+		return nil, err
+	}
+	for i := range bookmarks {
+		bookmarks[i].ArticleDeleted = bookmarks[i].Article == nil
+	}
+	return bookmarks, nil
+}
+
+// FindBookmarkCollections returns the user's bookmark collections, most recently used first.
+func (newsDbInterface *NewsDbInterface) FindBookmarkCollections(
+	ctx context.Context,
+	collName string,
+	userID string,
+) ([]newsArticle.BookmarkCollection, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching bookmark collections...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$collection",
+			"count":      bson.M{"$sum": 1},
+			"updated_at": bson.M{"$max": "$created_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	collections := []newsArticle.BookmarkCollection{}
+	if err := cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
@@ -0,0 +1,100 @@
+package dbInterface
+
+import (
+	"context"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (newsDbInterface *NewsDbInterface) FindArticlesWithin(
+	ctx context.Context,
+	collName string,
+	maxSize int64,
+	geometry utils.Geometry,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching news articles within area...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	// Create a filter with an opts
+	// For the 'location' filter, we use $geoWithin on the 2dsphere index to match articles inside the polygon.
+	// And finally, sort the result by publication_date in descending order.
+	filter := bson.M{"location": bson.M{"$geoWithin": bson.M{"$geometry": geometry}}}
+	applyArticleFilters(filter, filters)
+	opts := options.Find().SetSort(bson.D{primitive.E{Key: "publication_date", Value: -1}})
+	if maxSize > 0 {
+		opts.SetLimit(maxSize)
+	}
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var newsArticles []newsArticle.NewsArticleDBResponse
+	if err := cursor.All(ctx, &newsArticles); err != nil {
+		return nil, err
+	}
+
+	return newsArticles, nil
+}
+
+// FindArticleClusters buckets the articles inside the box into a grid of 'cellSizeDeg' degree cells.
+// The grid is anchored at (-180, -90) so a cell keeps its id while the map is panned.
+// Each bucket has its article count, the centroid of its articles and its top article by relevance_score.
+func (newsDbInterface *NewsDbInterface) FindArticleClusters(
+	ctx context.Context,
+	collName string,
+	maxClusters int64,
+	bbox utils.BBox,
+	cellSizeDeg float64,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.GeoCluster, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Aggregating news article clusters...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"location": bson.M{"$geoWithin": bson.M{"$geometry": bbox.Geometry()}}}
+	applyArticleFilters(filter, filters)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		// Sort first so that '$first' in the group picks the best article of each cell
+		{{Key: "$sort", Value: bson.D{
+			{Key: "relevance_score", Value: -1},
+			{Key: "publication_date", Value: -1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"x": bson.M{"$floor": bson.M{"$divide": bson.A{bson.M{"$add": bson.A{"$longitude", 180}}, cellSizeDeg}}},
+				"y": bson.M{"$floor": bson.M{"$divide": bson.A{bson.M{"$add": bson.A{"$latitude", 90}}, cellSizeDeg}}},
+			},
+			"count":              bson.M{"$sum": 1},
+			"centroid_latitude":  bson.M{"$avg": "$latitude"},
+			"centroid_longitude": bson.M{"$avg": "$longitude"},
+			"top_article":        bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+	}
+	if maxClusters > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: maxClusters}})
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clusters []newsArticle.GeoCluster
+	if err := cursor.All(ctx, &clusters); err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
@@ -0,0 +1,105 @@
+package dbInterface
+
+import (
+	"context"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddLLMUsage adds the calls, tokens and cost of 'usage' to the usage of its key with its model on its day.
+func (newsDbInterface *NewsDbInterface) AddLLMUsage(ctx context.Context, collName string, usage newsArticle.LLMUsage) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Adding LLM usage...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"api_key_id": usage.APIKeyID, "day": usage.Day, "model": usage.Model}
+	update := bson.M{
+		"$inc": bson.M{
+			"calls":             usage.Calls,
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"cost_usd":          usage.CostUSD,
+		},
+		"$set": bson.M{"updated_at": usage.UpdatedAt},
+	}
+	opts := options.Update().SetUpsert(true)
+
+	_, err := coll.UpdateOne(ctx, filter, update, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two first calls of the day raced to create the document, it exists now
+		_, err = coll.UpdateOne(ctx, filter, update, opts)
+	}
+	return err
+}
+
+// FindLLMSpend returns what the key spent on 'day' and from 'monthStart' to 'day', both "2006-01-02".
+func (newsDbInterface *NewsDbInterface) FindLLMSpend(
+	ctx context.Context,
+	collName string,
+	apiKeyID string,
+	day string,
+	monthStart string,
+) (newsArticle.LLMSpend, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching LLM spend...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"api_key_id": apiKeyID, "day": bson.M{"$gte": monthStart, "$lte": day}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         nil,
+			"monthly_usd": bson.M{"$sum": "$cost_usd"},
+			"daily_usd":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$day", day}}, "$cost_usd", 0}}},
+		}}},
+	}
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return newsArticle.LLMSpend{}, err
+	}
+	defer cursor.Close(ctx)
+
+	spends := []newsArticle.LLMSpend{}
+	if err := cursor.All(ctx, &spends); err != nil {
+		return newsArticle.LLMSpend{}, err
+	}
+	if len(spends) == 0 {
+		return newsArticle.LLMSpend{}, nil
+	}
+	return spends[0], nil
+}
+
+// FindLLMUsage returns the usage from 'from' to 'to', both "2006-01-02", of the key or of all keys when
+// 'apiKeyID' is nil, latest day first.
+func (newsDbInterface *NewsDbInterface) FindLLMUsage(
+	ctx context.Context,
+	collName string,
+	from string,
+	to string,
+	apiKeyID *string,
+) ([]newsArticle.LLMUsage, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching LLM usage...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"day": bson.M{"$gte": from, "$lte": to}}
+	if apiKeyID != nil {
+		filter["api_key_id"] = *apiKeyID
+	}
+	opts := options.Find().SetSort(bson.D{
+		primitive.E{Key: "day", Value: -1},
+		primitive.E{Key: "api_key_id", Value: 1},
+		primitive.E{Key: "model", Value: 1},
+	})
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	usages := []newsArticle.LLMUsage{}
+	if err := cursor.All(ctx, &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -3,10 +3,13 @@ package dbInterface
 import (
 	"context"
 	"log/slog"
-	"sort"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -30,6 +33,7 @@ func (newsDbInterface *NewsDbInterface) FindAllArticles(
 	ctx context.Context,
 	collName string,
 	maxSize int64,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching latest news articles...")
 	coll := newsDbInterface.DB.Collection(collName)
@@ -38,6 +42,7 @@ func (newsDbInterface *NewsDbInterface) FindAllArticles(
 	// Find all articles and
 	// Sort the result by publication_date in descending order
 	filter := bson.M{}
+	applyArticleFilters(filter, filters)
 	opts := options.Find().SetSort(bson.D{primitive.E{Key: "publication_date", Value: -1}})
 	if maxSize > 0 {
 		opts.SetLimit(maxSize)
@@ -63,6 +68,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesByCategory(
 	collName string,
 	maxSize int64,
 	category string,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching news articles by category...")
 
@@ -73,6 +79,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesByCategory(
 	// For example: if category is "Sports", it will match "Sports", "sports", "Sports Cricket", "cricket Sports", "sportnews" etc.
 	// And finally, sort the result by publication_date in descending order.
 	filter := bson.M{"category": bson.M{"$regex": primitive.Regex{Pattern: category, Options: "i"}}}
+	applyArticleFilters(filter, filters)
 	opts := options.Find().SetSort(bson.D{primitive.E{Key: "publication_date", Value: -1}})
 	if maxSize > 0 {
 		opts.SetLimit(maxSize)
@@ -97,6 +104,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesByScore(
 	collName string,
 	maxSize int64,
 	threshold float64,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching news articles by score...")
 	coll := newsDbInterface.DB.Collection(collName)
@@ -105,6 +113,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesByScore(
 	// For the 'threshold' filter, we use a range query to match scores greater than or equal to the specified threshold.
 	// And finally, sort the result by relevance_score in descending order.
 	filter := bson.M{"relevance_score": bson.M{"$gte": threshold}}
+	applyArticleFilters(filter, filters)
 	opts := options.Find().SetSort(bson.D{primitive.E{Key: "relevance_score", Value: -1}})
 	if maxSize > 0 {
 		opts.SetLimit(maxSize)
@@ -129,12 +138,14 @@ func (newsDbInterface *NewsDbInterface) FindArticlesBySearchQuery(
 	collName string,
 	maxSize int64,
 	query string,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Searching news articles...")
 	coll := newsDbInterface.DB.Collection(collName)
 
 	// Create a filter with an opts
 	filter := bson.M{"$text": bson.M{"$search": query}}
+	applyArticleFilters(filter, filters)
 	opts := options.Find()
     opts.SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}})
 
@@ -166,6 +177,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesBySource(
 	collName string,
 	maxSize int64,
 	source string,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching news articles by source...")
 	coll := newsDbInterface.DB.Collection(collName)
@@ -175,6 +187,7 @@ func (newsDbInterface *NewsDbInterface) FindArticlesBySource(
 	// for example: if source is "BBC", it will match "BBC", "bbc", "BBC News", "News BBC", "newsbbc" etc.
 	// And finally, sort the result by publication_date in descending order
 	filter := bson.M{"source_name": bson.M{"$regex": primitive.Regex{Pattern: source, Options: "i"}}}
+	applyArticleFilters(filter, filters)
 	opts := options.Find().SetSort(bson.D{primitive.E{Key: "publication_date", Value: -1}})
 	if maxSize > 0 {
 		opts.SetLimit(maxSize)
@@ -201,32 +214,51 @@ func (newsDbInterface *NewsDbInterface) FindArticlesNearby(
 	latitude float64,
 	longitude float64,
 	radius float64,
+	ranking newsArticle.NearbyRanking,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching nearby news articles...")
 	coll := newsDbInterface.DB.Collection(collName)
 
-	// Create a filter with an opts
-	// For the 'location' filter, we use a geospatial query to find articles near the specified coordinates.
+	// Create an aggregation pipeline
+	// We use '$geoNear' to find articles near the specified coordinates, which also gives the distance of each article in 'distance_km'.
 	// And we use a 'maxDistance' to limit the search to a specific radius.
-	// Finally, it sorts the results with nearest first
-	filter := bson.M{
-		"location": bson.M{
-			"$near": bson.M{
-				"$geometry": bson.M{
-					"type":        "Point",
-					"coordinates": bson.A{longitude, latitude},
-				},
-				"$maxDistance": radius * 1000, // radius in meters
+	// '$geoNear' returns the nearest first, which the 'recency' and 'blend' rankings re-sort.
+	query := bson.M{}
+	applyArticleFilters(query, filters)
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near": bson.M{
+				"type":        "Point",
+				"coordinates": bson.A{longitude, latitude},
 			},
-		},
+			"distanceField":      "distance_km",
+			"distanceMultiplier": 0.001,         // meters to kilometers
+			"maxDistance":        radius * 1000, // radius in meters
+			"spherical":          true,
+			"query":              query,
+		}}},
 	}
-	
-	opts := options.Find()
+
+	switch ranking.Rank {
+	case newsArticle.NEARBY_RANK_RECENCY:
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "publication_date", Value: -1},
+			{Key: "distance_km", Value: 1},
+		}}})
+
+	case newsArticle.NEARBY_RANK_BLEND:
+		pipeline = append(pipeline,
+			bson.D{{Key: "$addFields", Value: bson.M{"rank_score": blendedNearbyScore(ranking)}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "rank_score", Value: -1}}}},
+		)
+	}
+
 	if maxSize > 0 {
-		opts.SetLimit(maxSize)
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: maxSize}})
 	}
 
-	cursor, err := coll.Find(ctx, filter, opts)
+	cursor, err := coll.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -240,77 +272,101 @@ func (newsDbInterface *NewsDbInterface) FindArticlesNearby(
 	return newsArticles, nil
 }
 
-func (newsDbInterface *NewsDbInterface) InsertUserEvent(ctx context.Context, event newsArticle.UserEvent) error {
-	newsDbInterface.Logger.Debug("'Data Layer': Inserting user event...")
-	coll := newsDbInterface.DB.Collection(constants.USER_EVENT)
-
-	_, err := coll.InsertOne(ctx, event)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// blendedNearbyScore builds the '$addFields' expression for the 'blend' nearby ranking.
+// Proximity and recency both decay by half every DistanceHalfKm and RecencyHalfLife,
+// and an unparsable publication_date counts as very old.
+func blendedNearbyScore(ranking newsArticle.NearbyRanking) bson.M {
+	publishedAt := bson.M{"$dateFromString": bson.M{
+		"dateString": "$publication_date",
+		"onError":    time.Unix(0, 0),
+		"onNull":     time.Unix(0, 0),
+	}}
+	ageHours := bson.M{"$max": bson.A{0, bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$$NOW", publishedAt}}, float64(time.Hour / time.Millisecond)}}}}
+
+	proximity := bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{"$distance_km", ranking.DistanceHalfKm}}}}
+	recency := bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{ageHours, ranking.RecencyHalfLife.Hours()}}}}
+	relevance := bson.M{"$ifNull": bson.A{"$relevance_score", 0}}
+
+	return bson.M{"$add": bson.A{
+		bson.M{"$multiply": bson.A{ranking.DistanceWeight, proximity}},
+		bson.M{"$multiply": bson.A{ranking.RecencyWeight, recency}},
+		bson.M{"$multiply": bson.A{ranking.RelevanceWeight, relevance}},
+	}}
 }
 
 func (newsDbInterface *NewsDbInterface) FindTrendingArticles(
 	ctx context.Context,
 	newsCollName string,
 	userEventCollName string,
-	maxSize int64, 
-	latitude float64, 
-	longitude float64, 
-	radius float64,
+	maxSize int64,
+	scoring newsArticle.TrendingScoring,
+	location *newsArticle.TrendingLocation,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	newsDbInterface.Logger.Debug("'Data Layer': Fetching trending news articles...")
-	coll := newsDbInterface.DB.Collection(newsCollName)
-
-	
-	// get all the user-events 
-	userEvents, err := newsDbInterface.GetAllUserEvents(ctx, userEventCollName)
-	if err != nil {
-		return nil, err
+	coll := newsDbInterface.DB.Collection(userEventCollName)
+
+	// Create an aggregation pipeline over the user events
+	// 1. Only the events of the window with a weighted event type are scored.
+	//    With the 'events' or 'both' scope, only the events which happened within the radius.
+	// 2. Each event adds its type's weight, decayed by half every 'HalfLife', to its article's score.
+	// 3. The articles are joined in trend order, dropping deleted articles and the ones not matching the filters.
+	//    With the 'articles' or 'both' scope, only the articles located within the radius.
+	eventTypes := bson.A{}
+	for eventType := range scoring.EventWeights {
+		eventTypes = append(eventTypes, eventType)
 	}
-
-	newsDbInterface.Logger.Debug("====> Fetched all user events for trending articles: ", "events", userEvents)
-
-	// Calculate the user events scores
-	trendingScores := make(map[string]float64)
-	for _, event := range userEvents {
-		score := 1.0 // for view
-		if event.EventType == "click" {
-			score = 3.0 // for click
-		}
-		trendingScores[event.ArticleID] += score
+	eventFilter := bson.M{
+		"timestamp":  bson.M{"$gte": scoring.Now.Add(-scoring.Window), "$lte": scoring.Now},
+		"event_type": bson.M{"$in": eventTypes},
 	}
-
-	// sort the userEvent by score
-	type kv struct {
-		Key   string
-		Value float64
+	if len(filters.ExcludeArticleIDs) > 0 {
+		// Also excluded by the article filter, but cheaper to skip before grouping
+		eventFilter["article_id"] = bson.M{"$nin": filters.ExcludeArticleIDs}
 	}
-	var sortedScores []kv
-	for k, v := range trendingScores {
-		sortedScores = append(sortedScores, kv{k, v})
+	articleFilter := bson.M{}
+	applyArticleFilters(articleFilter, filters)
+
+	if location != nil {
+		// '$centerSphere' takes the radius in radians
+		withinRadius := bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{
+			bson.A{location.Longitude, location.Latitude},
+			location.RadiusKm / utils.EarthRadiusKm,
+		}}}
+		if location.Scope != newsArticle.TRENDING_SCOPE_ARTICLES {
+			eventFilter["location"] = withinRadius
+		}
+		if location.Scope != newsArticle.TRENDING_SCOPE_EVENTS {
+			articleFilter["location"] = withinRadius
+		}
 	}
-	sort.Slice(sortedScores, func(i, j int) bool {
-		return sortedScores[i].Value > sortedScores[j].Value
-	})
 
-	// get the top N trending articles ids
-	var trendingArticleIDs []string
-	for i := 0; i < int(maxSize) && i < len(sortedScores); i++ {
-		trendingArticleIDs = append(trendingArticleIDs, sortedScores[i].Key)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: eventFilter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$article_id",
+			"trend_score": bson.M{"$sum": decayedEventWeight(scoring)},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "trend_score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": newsCollName,
+			"let":  bson.M{"article_id": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$article_id"}}}},
+				bson.M{"$match": articleFilter},
+			},
+			"as": "article",
+		}}},
+		{{Key: "$unwind", Value: "$article"}},
 	}
-
-	// If no valid article IDs found, return empty slice
-	if len(trendingArticleIDs) == 0 {
-		return []newsArticle.NewsArticleDBResponse{}, nil
+	if maxSize > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: maxSize}})
 	}
+	pipeline = append(pipeline, bson.D{{Key: "$replaceRoot", Value: bson.M{
+		"newRoot": bson.M{"$mergeObjects": bson.A{"$article", bson.M{"trend_score": "$trend_score"}}},
+	}}})
 
-	filter := bson.M{"_id": bson.M{"$in": trendingArticleIDs}}
-	opts := options.Find().SetSort(bson.D{primitive.E{Key: "timestamp", Value: -1}})
-
-	cursor, err := coll.Find(ctx, filter, opts)
+	cursor, err := coll.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -321,26 +377,114 @@ func (newsDbInterface *NewsDbInterface) FindTrendingArticles(
 		return nil, err
 	}
 
+	for i := range newsArticles {
+		newsArticles[i].TrendRank = i + 1
+	}
+
 	return newsArticles, nil
 }
 
-func (newsDbInterface *NewsDbInterface) GetAllUserEvents(ctx context.Context, collName string) ([]newsArticle.UserEvent, error) {
-	newsDbInterface.Logger.Debug("'Data Layer': Fetching all user events...")
-	coll := newsDbInterface.DB.Collection(collName)
+// decayedEventWeight is the score of a single event: its type's weight halved for every 'HalfLife' of age.
+func decayedEventWeight(scoring newsArticle.TrendingScoring) bson.M {
+	branches := bson.A{}
+	for eventType, weight := range scoring.EventWeights {
+		branches = append(branches, bson.M{
+			"case": bson.M{"$eq": bson.A{"$event_type", eventType}},
+			"then": weight,
+		})
+	}
+	weight := bson.M{"$switch": bson.M{"branches": branches, "default": 0}}
 
-	filter := bson.M{}
-	opts := options.Find().SetSort(bson.D{primitive.E{Key: "timestamp", Value: -1}})
+	ageMillis := bson.M{"$subtract": bson.A{scoring.Now, "$timestamp"}}
+	decay := bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{ageMillis, float64(scoring.HalfLife.Milliseconds())}}}}
 
-	cursor, err := coll.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, err
+	return bson.M{"$multiply": bson.A{weight, decay}}
+}
+
+// applyArticleFilters adds the optional listing filters to a query filter.
+// Region is matched case-insensitively and exactly, so "jharkhand" matches "Jharkhand" but not "West Jharkhand".
+func applyArticleFilters(filter bson.M, filters newsArticle.ArticleFilters) {
+	if filters.CountryCode != "" {
+		addCondition(filter, "country_code", strings.ToUpper(filters.CountryCode))
 	}
-	defer cursor.Close(ctx)
+	if filters.Region != "" {
+		addCondition(filter, "region", bson.M{"$regex": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filters.Region) + "$", Options: "i"}})
+	}
+	if filters.Category != "" {
+		addCondition(filter, "category", bson.M{"$regex": primitive.Regex{Pattern: regexp.QuoteMeta(filters.Category), Options: "i"}})
+	}
+	if filters.Source != "" {
+		addCondition(filter, "source_name", bson.M{"$regex": primitive.Regex{Pattern: regexp.QuoteMeta(filters.Source), Options: "i"}})
+	}
+	if filters.MinScore > 0 {
+		addCondition(filter, "relevance_score", bson.M{"$gte": filters.MinScore})
+	}
+	if !filters.PublishedFrom.IsZero() || !filters.PublishedTo.IsZero() {
+		dateRange := bson.M{}
+		if !filters.PublishedFrom.IsZero() {
+			dateRange["$gte"] = filters.PublishedFrom.UTC().Format(constants.PUBLICATION_DATE_LAYOUT)
+		}
+		if !filters.PublishedTo.IsZero() {
+			dateRange["$lte"] = filters.PublishedTo.UTC().Format(constants.PUBLICATION_DATE_LAYOUT)
+		}
+		addCondition(filter, "publication_date", dateRange)
+	}
+	if len(filters.MutedSources) > 0 {
+		addCondition(filter, "source_name", bson.M{"$nin": exactPatterns(filters.MutedSources)})
+	}
+	if len(filters.MutedKeywords) > 0 {
+		muted := bson.A{}
+		for _, keyword := range filters.MutedKeywords {
+			pattern := primitive.Regex{Pattern: `\b` + regexp.QuoteMeta(keyword) + `\b`, Options: "i"}
+			muted = append(muted, bson.M{"title": pattern}, bson.M{"description": pattern})
+		}
+		addCondition(filter, "$nor", muted)
+	}
+	if filters.Following != nil {
+		addCondition(filter, "$or", followingConditions(*filters.Following))
+	}
+	if len(filters.ExcludeArticleIDs) > 0 {
+		addCondition(filter, "_id", bson.M{"$nin": filters.ExcludeArticleIDs})
+	}
+}
 
-	var userEvents []newsArticle.UserEvent
-	if err := cursor.All(ctx, &userEvents); err != nil {
-		return nil, err
+// followingConditions matches the articles in a followed category, from a followed source or within a followed place.
+// Without any follows it matches nothing.
+func followingConditions(follows newsArticle.Follows) bson.A {
+	conditions := bson.A{}
+	if len(follows.Categories) > 0 {
+		conditions = append(conditions, bson.M{"category": bson.M{"$in": exactPatterns(follows.Categories)}})
+	}
+	if len(follows.Sources) > 0 {
+		conditions = append(conditions, bson.M{"source_name": bson.M{"$in": exactPatterns(follows.Sources)}})
+	}
+	for _, place := range follows.Places {
+		conditions = append(conditions, bson.M{"location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{place.Longitude, place.Latitude}, place.RadiusKm / utils.EarthRadiusKm},
+		}}})
 	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, bson.M{"_id": bson.M{"$in": bson.A{}}})
+	}
+	return conditions
+}
 
-	return userEvents, nil
-}
\ No newline at end of file
+// exactPatterns are case-insensitive regexes matching each value exactly, for '$in' and '$nin'.
+func exactPatterns(values []string) bson.A {
+	patterns := bson.A{}
+	for _, value := range values {
+		patterns = append(patterns, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"})
+	}
+	return patterns
+}
+
+// addCondition sets a condition on a field, moving it into '$and' when the query already constrains that field
+// (e.g. the '/category' endpoint combined with a 'category' filter).
+func addCondition(filter bson.M, key string, condition interface{}) {
+	if _, exists := filter[key]; !exists {
+		filter[key] = condition
+		return
+	}
+	and, _ := filter["$and"].(bson.A)
+	filter["$and"] = append(and, bson.M{key: condition})
+}
@@ -0,0 +1,90 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RateLimitStore keeps the token buckets of the rate limiter in a collection, so that all the instances
+// of the API share them. It implements ratelimit.Store.
+type RateLimitStore struct {
+	DbInterface *NewsDbInterface
+	CollName    string
+}
+
+func NewRateLimitStore(newsDbInterface *NewsDbInterface, collName string) *RateLimitStore {
+	return &RateLimitStore{DbInterface: newsDbInterface, CollName: collName}
+}
+
+// Take refills the bucket at 'key' and takes a token from it in a single update, creating the bucket when needed.
+// Buckets get an expires_at when they will be full again, after which the TTL index removes them.
+func (store *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	store.DbInterface.Logger.Debug("'Data Layer': Taking rate limit token...")
+	coll := store.DbInterface.DB.Collection(store.CollName)
+
+	now = now.UTC().Truncate(time.Millisecond)
+	update := mongo.Pipeline{
+		// Elapsed milliseconds are never negative, in case another instance's clock is ahead
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{
+				limit.Burst,
+				bson.M{"$add": bson.A{
+					bson.M{"$ifNull": bson.A{"$tokens", limit.Burst}},
+					bson.M{"$multiply": bson.A{
+						limit.Rate / 1000,
+						bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}},
+					}},
+				}},
+			}},
+			"updated_at": bson.M{"$max": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens":     bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			"expires_at": now.Add(ratelimit.FullAfter(limit)),
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bucket struct {
+		Tokens  float64 `bson:"tokens"`
+		Allowed bool    `bson:"allowed"`
+	}
+	err := coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two first requests raced to create the bucket, it exists now
+		err = coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&bucket)
+	}
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	return ratelimit.NewResult(bucket.Allowed, bucket.Tokens, limit), nil
+}
+
+// Peek reads the bucket at 'key' refilled until 'now', without taking a token. A missing bucket is full.
+func (store *RateLimitStore) Peek(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	store.DbInterface.Logger.Debug("'Data Layer': Reading rate limit bucket...")
+	coll := store.DbInterface.DB.Collection(store.CollName)
+
+	var bucket struct {
+		Tokens    float64   `bson:"tokens"`
+		UpdatedAt time.Time `bson:"updated_at"`
+	}
+	err := coll.FindOne(ctx, bson.M{"_id": key}).Decode(&bucket)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ratelimit.NewResult(true, limit.Burst, limit), nil
+	}
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	tokens := ratelimit.Refill(bucket.Tokens, bucket.UpdatedAt, limit, now)
+	return ratelimit.NewResult(tokens >= 1, tokens, limit), nil
+}
@@ -0,0 +1,64 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindReadHistory returns the read history of the user, or nil when they have none yet.
+func (newsDbInterface *NewsDbInterface) FindReadHistory(
+	ctx context.Context,
+	collName string,
+	userID string,
+) (*newsArticle.ReadHistory, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching read history...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var history newsArticle.ReadHistory
+	err := coll.FindOne(ctx, bson.M{"_id": userID}).Decode(&history)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// AddToReadHistory appends the articles, oldest first, to the user's read history, moving the ones
+// already there to the end, and keeps the latest 'maxSize'. The history is created when needed.
+func (newsDbInterface *NewsDbInterface) AddToReadHistory(
+	ctx context.Context,
+	collName string,
+	userID string,
+	articleIDs []string,
+	maxSize int,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Updating read history...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"article_ids": bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{
+					bson.M{"$filter": bson.M{
+						"input": bson.M{"$ifNull": bson.A{"$article_ids", bson.A{}}},
+						"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this", articleIDs}}}},
+					}},
+					articleIDs,
+				}},
+				-maxSize,
+			}},
+			"updated_at": time.Now(),
+		}}},
+	}
+
+	_, err := coll.UpdateOne(ctx, bson.M{"_id": userID}, update, options.Update().SetUpsert(true))
+	return err
+}
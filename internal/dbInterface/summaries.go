@@ -0,0 +1,61 @@
+package dbInterface
+
+import (
+	"context"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindSummaries returns the stored summaries of the articles with the model and prompt version, by article ID.
+// They may be stale, see ArticleSummary.ContentHash.
+func (newsDbInterface *NewsDbInterface) FindSummaries(
+	ctx context.Context,
+	collName string,
+	articleIDs []string,
+	model string,
+	promptVersion string,
+) (map[string]newsArticle.ArticleSummary, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching article summaries...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	summaries := map[string]newsArticle.ArticleSummary{}
+	if len(articleIDs) == 0 {
+		return summaries, nil
+	}
+
+	filter := bson.M{"article_id": bson.M{"$in": articleIDs}, "model": model, "prompt_version": promptVersion}
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var summary newsArticle.ArticleSummary
+		if err := cursor.Decode(&summary); err != nil {
+			return nil, err
+		}
+		summaries[summary.ArticleID] = summary
+	}
+	return summaries, cursor.Err()
+}
+
+// SaveSummary stores the summary, replacing the one of its article, model and prompt version,
+// which is stale when the article was edited since.
+func (newsDbInterface *NewsDbInterface) SaveSummary(ctx context.Context, collName string, summary newsArticle.ArticleSummary) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Saving article summary...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"article_id": summary.ArticleID, "model": summary.Model, "prompt_version": summary.PromptVersion}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := coll.ReplaceOne(ctx, filter, summary, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another request stored the summary first
+		_, err = coll.ReplaceOne(ctx, filter, summary, opts)
+	}
+	return err
+}
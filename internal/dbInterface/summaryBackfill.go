@@ -0,0 +1,153 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindArticlesForSummaries pages through the articles matching the filters, newest first, starting after
+// the article of the checkpoint when there is one. Only what summaries are generated from is returned.
+func (newsDbInterface *NewsDbInterface) FindArticlesForSummaries(
+	ctx context.Context,
+	collName string,
+	batchSize int64,
+	filters newsArticle.ArticleFilters,
+	after *newsArticle.SummaryCheckpoint,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching articles for summaries...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{}
+	applyArticleFilters(filter, filters)
+	if after != nil {
+		addCondition(filter, "$or", bson.A{
+			bson.M{"publication_date": bson.M{"$lt": after.PublicationDate}},
+			bson.M{"publication_date": after.PublicationDate, "_id": bson.M{"$lt": after.ArticleID}},
+		})
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "publication_date", Value: -1}, primitive.E{Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"_id": 1, "title": 1, "description": 1, "publication_date": 1}).
+		SetLimit(batchSize)
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var newsArticles []newsArticle.NewsArticleDBResponse
+	if err := cursor.All(ctx, &newsArticles); err != nil {
+		return nil, err
+	}
+
+	return newsArticles, nil
+}
+
+// FindSummaryFailures returns the pre-generation failures of the articles, by article ID.
+func (newsDbInterface *NewsDbInterface) FindSummaryFailures(
+	ctx context.Context,
+	collName string,
+	articleIDs []string,
+) (map[string]newsArticle.SummaryFailure, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching summary failures...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	failures := map[string]newsArticle.SummaryFailure{}
+	if len(articleIDs) == 0 {
+		return failures, nil
+	}
+
+	cursor, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": articleIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var failure newsArticle.SummaryFailure
+		if err := cursor.Decode(&failure); err != nil {
+			return nil, err
+		}
+		failures[failure.ArticleID] = failure
+	}
+	return failures, cursor.Err()
+}
+
+// SaveSummaryFailure replaces the pre-generation failure of the article.
+func (newsDbInterface *NewsDbInterface) SaveSummaryFailure(
+	ctx context.Context,
+	collName string,
+	failure newsArticle.SummaryFailure,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Saving summary failure...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.ReplaceOne(ctx, bson.M{"_id": failure.ArticleID}, failure, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeleteSummaryFailure forgets the pre-generation failure of the article, once its summary is stored.
+func (newsDbInterface *NewsDbInterface) DeleteSummaryFailure(
+	ctx context.Context,
+	collName string,
+	articleID string,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Deleting summary failure...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.DeleteOne(ctx, bson.M{"_id": articleID})
+	return err
+}
+
+// FindSummaryCheckpoint returns the checkpoint of the job, nil when there is none.
+func (newsDbInterface *NewsDbInterface) FindSummaryCheckpoint(
+	ctx context.Context,
+	collName string,
+	job string,
+) (*newsArticle.SummaryCheckpoint, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching summary checkpoint...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var checkpoint newsArticle.SummaryCheckpoint
+	err := coll.FindOne(ctx, bson.M{"_id": job}).Decode(&checkpoint)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveSummaryCheckpoint replaces the checkpoint of the job.
+func (newsDbInterface *NewsDbInterface) SaveSummaryCheckpoint(
+	ctx context.Context,
+	collName string,
+	checkpoint newsArticle.SummaryCheckpoint,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Saving summary checkpoint...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.ReplaceOne(ctx, bson.M{"_id": checkpoint.Job}, checkpoint, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeleteSummaryCheckpoint removes the checkpoint of the job, so that its next pass starts from the newest article.
+func (newsDbInterface *NewsDbInterface) DeleteSummaryCheckpoint(
+	ctx context.Context,
+	collName string,
+	job string,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Deleting summary checkpoint...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.DeleteOne(ctx, bson.M{"_id": job})
+	return err
+}
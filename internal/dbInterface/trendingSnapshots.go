@@ -0,0 +1,170 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AggregateTrendingScoresByCell scores the events of the window like FindTrendingArticles,
+// but keeps the score of each article per 'cellSizeDeg' degree grid cell of where the events happened.
+func (newsDbInterface *NewsDbInterface) AggregateTrendingScoresByCell(
+	ctx context.Context,
+	userEventCollName string,
+	scoring newsArticle.TrendingScoring,
+	cellSizeDeg float64,
+) ([]newsArticle.TrendingCellScore, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Aggregating trending scores by cell...")
+	coll := newsDbInterface.DB.Collection(userEventCollName)
+
+	eventTypes := bson.A{}
+	for eventType := range scoring.EventWeights {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	// The cell is taken from the GeoJSON location, so events without one are only counted globally
+	cellIndex := func(coordinate string, offset float64) bson.M {
+		return bson.M{"$cond": bson.A{
+			bson.M{"$ifNull": bson.A{"$location", false}},
+			bson.M{"$floor": bson.M{"$divide": bson.A{bson.M{"$add": bson.A{coordinate, offset}}, cellSizeDeg}}},
+			nil,
+		}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"timestamp":  bson.M{"$gte": scoring.Now.Add(-scoring.Window), "$lte": scoring.Now},
+			"event_type": bson.M{"$in": eventTypes},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"article_id": "$article_id",
+				"x":          cellIndex("$longitude", 180),
+				"y":          cellIndex("$latitude", 90),
+			},
+			"score": bson.M{"$sum": decayedEventWeight(scoring)},
+		}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var scores []newsArticle.TrendingCellScore
+	if err := cursor.All(ctx, &scores); err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// FindArticlesByIDs returns the articles with the given IDs which match the filters, in no particular order.
+func (newsDbInterface *NewsDbInterface) FindArticlesByIDs(
+	ctx context.Context,
+	collName string,
+	articleIDs []string,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching news articles by IDs...")
+	if len(articleIDs) == 0 {
+		return []newsArticle.NewsArticleDBResponse{}, nil
+	}
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{"_id": bson.M{"$in": articleIDs}}
+	applyArticleFilters(filter, filters)
+
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var newsArticles []newsArticle.NewsArticleDBResponse
+	if err := cursor.All(ctx, &newsArticles); err != nil {
+		return nil, err
+	}
+
+	return newsArticles, nil
+}
+
+func (newsDbInterface *NewsDbInterface) InsertTrendingSnapshots(
+	ctx context.Context,
+	collName string,
+	snapshots []newsArticle.TrendingSnapshot,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Inserting trending snapshots...")
+	if len(snapshots) == 0 {
+		return nil
+	}
+	coll := newsDbInterface.DB.Collection(collName)
+
+	documents := make([]interface{}, len(snapshots))
+	for i := range snapshots {
+		documents[i] = snapshots[i]
+	}
+
+	_, err := coll.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// FindTrendingSnapshots returns the snapshots of the window in the given cells from the latest build at or before 'asOf'.
+// Category "" returns the snapshots of every category, any other category the snapshots of every category containing it,
+// case-insensitively, like the category filter of the listings. It also returns the time of the build, nil when no
+// snapshot was built by then.
+func (newsDbInterface *NewsDbInterface) FindTrendingSnapshots(
+	ctx context.Context,
+	collName string,
+	window string,
+	category string,
+	cells []string,
+	asOf time.Time,
+) ([]newsArticle.TrendingSnapshot, *time.Time, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching trending snapshots...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	// Every snapshot of a build has its as_of, so a cell or category without one had no trending articles then
+	latestOpts := options.FindOne().
+		SetSort(bson.D{primitive.E{Key: "as_of", Value: -1}}).
+		SetProjection(bson.M{"as_of": 1})
+	var latest newsArticle.TrendingSnapshot
+	err := coll.FindOne(ctx, bson.M{"window": window, "as_of": bson.M{"$lte": asOf}}, latestOpts).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := bson.M{
+		"window":   window,
+		"category": category,
+		"cell":     bson.M{"$in": cells},
+		"as_of":    latest.AsOf,
+	}
+	if category != "" {
+		filter["category"] = bson.M{"$regex": primitive.Regex{Pattern: regexp.QuoteMeta(category), Options: "i"}}
+	}
+
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []newsArticle.TrendingSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, nil, err
+	}
+
+	return snapshots, &latest.AsOf, nil
+}
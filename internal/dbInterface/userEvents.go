@@ -0,0 +1,121 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillUserEventLocations sets the GeoJSON 'location' of the events stored before it existed,
+// from their latitude and longitude. It returns the number of updated events.
+// Events from the ingestion API (with a client_event_id) already have a location when they have one at all.
+func (newsDbInterface *NewsDbInterface) BackfillUserEventLocations(ctx context.Context, collName string) (int64, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Backfilling user event locations...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{
+		"location":        bson.M{"$exists": false},
+		"client_event_id": bson.M{"$exists": false},
+		"latitude":        bson.M{"$type": "number"},
+		"longitude":       bson.M{"$type": "number"},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"location": bson.M{
+				"type":        "Point",
+				"coordinates": bson.A{"$longitude", "$latitude"},
+			},
+		}}},
+	}
+
+	result, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// InsertUserEvents inserts the events without stopping at the first failure.
+// It returns the indexes of the events rejected by the unique client_event_id index,
+// i.e. duplicates which were inserted concurrently by another request.
+func (newsDbInterface *NewsDbInterface) InsertUserEvents(
+	ctx context.Context,
+	collName string,
+	events []newsArticle.UserEvent,
+) (map[int]bool, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Inserting user events...")
+	duplicates := map[int]bool{}
+	if len(events) == 0 {
+		return duplicates, nil
+	}
+	coll := newsDbInterface.DB.Collection(collName)
+
+	documents := make([]interface{}, len(events))
+	for i := range events {
+		documents[i] = events[i]
+	}
+
+	_, err := coll.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return nil, err
+			}
+			duplicates[writeErr.Index] = true
+		}
+		return duplicates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// FindExistingClientEventIDs returns which of the client event IDs are already stored.
+func (newsDbInterface *NewsDbInterface) FindExistingClientEventIDs(
+	ctx context.Context,
+	collName string,
+	clientEventIDs []string,
+) (map[string]bool, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Checking existing client event IDs...")
+	return newsDbInterface.findExistingValues(ctx, collName, "client_event_id", clientEventIDs)
+}
+
+// FindExistingArticleIDs returns which of the article IDs exist in the news collection.
+func (newsDbInterface *NewsDbInterface) FindExistingArticleIDs(
+	ctx context.Context,
+	collName string,
+	articleIDs []string,
+) (map[string]bool, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Checking existing article IDs...")
+	return newsDbInterface.findExistingValues(ctx, collName, "_id", articleIDs)
+}
+
+func (newsDbInterface *NewsDbInterface) findExistingValues(
+	ctx context.Context,
+	collName string,
+	field string,
+	values []string,
+) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(values) == 0 {
+		return existing, nil
+	}
+	coll := newsDbInterface.DB.Collection(collName)
+
+	found, err := coll.Distinct(ctx, field, bson.M{field: bson.M{"$in": values}})
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range found {
+		if str, ok := value.(string); ok {
+			existing[str] = true
+		}
+	}
+	return existing, nil
+}
@@ -0,0 +1,133 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindUserProfile returns the stored profile of the user, or nil when there is none.
+func (newsDbInterface *NewsDbInterface) FindUserProfile(
+	ctx context.Context,
+	collName string,
+	userID string,
+) (*newsArticle.UserProfile, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching user profile...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var profile newsArticle.UserProfile
+	err := coll.FindOne(ctx, bson.M{"_id": userID}).Decode(&profile)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (newsDbInterface *NewsDbInterface) SaveUserProfile(
+	ctx context.Context,
+	collName string,
+	profile newsArticle.UserProfile,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Saving user profile...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.ReplaceOne(ctx, bson.M{"_id": profile.UserID}, profile, options.Replace().SetUpsert(true))
+	return err
+}
+
+// FindUserEvents returns the latest 'maxSize' events of the given types by the user since 'since', oldest first.
+func (newsDbInterface *NewsDbInterface) FindUserEvents(
+	ctx context.Context,
+	collName string,
+	userID string,
+	eventTypes []string,
+	since time.Time,
+	maxSize int64,
+) ([]newsArticle.UserEvent, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching user events...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{
+		"user_id":    userID,
+		"event_type": bson.M{"$in": eventTypes},
+		"timestamp":  bson.M{"$gte": since},
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "timestamp", Value: -1}}).
+		SetLimit(maxSize)
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []newsArticle.UserEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// FindFeedCandidates fetches, in one query, the latest 'perGroup' articles overall,
+// in any of the categories and from any of the sources. Categories and sources match case-insensitively.
+func (newsDbInterface *NewsDbInterface) FindFeedCandidates(
+	ctx context.Context,
+	collName string,
+	perGroup int64,
+	categories []string,
+	sources []string,
+	filters newsArticle.ArticleFilters,
+) (newsArticle.FeedCandidates, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching feed candidates...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	filter := bson.M{}
+	applyArticleFilters(filter, filters)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{primitive.E{Key: "publication_date", Value: -1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"latest": bson.A{
+				bson.M{"$limit": perGroup},
+			},
+			"categories": bson.A{
+				bson.M{"$match": bson.M{"category": bson.M{"$in": exactPatterns(categories)}}},
+				bson.M{"$limit": perGroup},
+			},
+			"sources": bson.A{
+				bson.M{"$match": bson.M{"source_name": bson.M{"$in": exactPatterns(sources)}}},
+				bson.M{"$limit": perGroup},
+			},
+		}}},
+	}
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return newsArticle.FeedCandidates{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []newsArticle.FeedCandidates
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return newsArticle.FeedCandidates{}, err
+	}
+	if len(candidates) == 0 {
+		return newsArticle.FeedCandidates{}, nil
+	}
+	return candidates[0], nil
+}
@@ -0,0 +1,127 @@
+package dbInterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindUserPreferences returns the preferences of the user, or nil when they have none.
+func (newsDbInterface *NewsDbInterface) FindUserPreferences(
+	ctx context.Context,
+	collName string,
+	userID string,
+) (*newsArticle.UserPreferences, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Fetching user preferences...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	var preferences newsArticle.UserPreferences
+	err := coll.FindOne(ctx, bson.M{"_id": userID}).Decode(&preferences)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// ReplaceUserPreferences stores the preferences, replacing the existing ones.
+func (newsDbInterface *NewsDbInterface) ReplaceUserPreferences(
+	ctx context.Context,
+	collName string,
+	preferences newsArticle.UserPreferences,
+) error {
+	newsDbInterface.Logger.Debug("'Data Layer': Replacing user preferences...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	_, err := coll.ReplaceOne(ctx, bson.M{"_id": preferences.UserID}, preferences, options.Replace().SetUpsert(true))
+	return err
+}
+
+// UpdateUserPreferences applies 'update' to the user's preferences, creating them when needed,
+// and returns the updated preferences.
+func (newsDbInterface *NewsDbInterface) UpdateUserPreferences(
+	ctx context.Context,
+	collName string,
+	userID string,
+	update interface{},
+) (*newsArticle.UserPreferences, error) {
+	newsDbInterface.Logger.Debug("'Data Layer': Updating user preferences...")
+	coll := newsDbInterface.DB.Collection(collName)
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var preferences newsArticle.UserPreferences
+	if err := coll.FindOneAndUpdate(ctx, bson.M{"_id": userID}, update, opts).Decode(&preferences); err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// AddUserPreference adds the value to a list of the user's preferences, unless it is already there.
+func (newsDbInterface *NewsDbInterface) AddUserPreference(
+	ctx context.Context,
+	collName string,
+	userID string,
+	field string,
+	value string,
+) (*newsArticle.UserPreferences, error) {
+	return newsDbInterface.UpdateUserPreferences(ctx, collName, userID, bson.M{
+		"$addToSet": bson.M{field: value},
+		"$set":      bson.M{"updated_at": time.Now()},
+	})
+}
+
+// RemoveUserPreference removes the value from a list of the user's preferences.
+func (newsDbInterface *NewsDbInterface) RemoveUserPreference(
+	ctx context.Context,
+	collName string,
+	userID string,
+	field string,
+	value string,
+) (*newsArticle.UserPreferences, error) {
+	return newsDbInterface.UpdateUserPreferences(ctx, collName, userID, bson.M{
+		"$pull": bson.M{field: value},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+}
+
+// SaveFollowedPlace adds the place to the user's followed places, replacing a place with the same name.
+func (newsDbInterface *NewsDbInterface) SaveFollowedPlace(
+	ctx context.Context,
+	collName string,
+	userID string,
+	place newsArticle.SavedPlace,
+) (*newsArticle.UserPreferences, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"followed_places": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$followed_places", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this.name", place.Name}},
+				}},
+				bson.A{place},
+			}},
+			"updated_at": time.Now(),
+		}}},
+	}
+	return newsDbInterface.UpdateUserPreferences(ctx, collName, userID, update)
+}
+
+// RemoveFollowedPlace removes the followed place with the name.
+func (newsDbInterface *NewsDbInterface) RemoveFollowedPlace(
+	ctx context.Context,
+	collName string,
+	userID string,
+	name string,
+) (*newsArticle.UserPreferences, error) {
+	return newsDbInterface.UpdateUserPreferences(ctx, collName, userID, bson.M{
+		"$pull": bson.M{"followed_places": bson.M{"name": name}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+}
@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+type APIKeyHandler struct {
+	APIKeyService   *services.APIKeyService
+	LLMUsageService *services.LLMUsageService
+	Logger          *slog.Logger
+}
+
+func NewAPIKeyHandler(
+	apiKeyService *services.APIKeyService,
+	llmUsageService *services.LLMUsageService,
+	logger *slog.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService:   apiKeyService,
+		LLMUsageService: llmUsageService,
+		Logger:          logger,
+	}
+}
+
+func (apiKeyHandler *APIKeyHandler) CreateAPIKeyHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Creating API key...")
+
+	var req newsArticle.APIKeyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	key, err := apiKeyHandler.APIKeyService.CreateAPIKey(c.Request.Context(), req)
+	if errors.Is(err, services.ErrInvalidAPIKeyInput) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid API key",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to create API key",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusCreated,
+		"Successfully created API key, store the key now as it is not shown again",
+		key,
+		1,
+		nil,
+	)
+}
+
+func (apiKeyHandler *APIKeyHandler) ListAPIKeysHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Fetching API keys...")
+
+	keys, err := apiKeyHandler.APIKeyService.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve API keys",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved API keys",
+		keys,
+		len(keys),
+		nil,
+	)
+}
+
+func (apiKeyHandler *APIKeyHandler) RotateAPIKeyHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Rotating API key...")
+
+	key, err := apiKeyHandler.APIKeyService.RotateAPIKey(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusNotFound,
+			"API key not found or revoked",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to rotate API key",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusOK,
+		"Successfully rotated API key, store the new key now as it is not shown again",
+		key,
+		1,
+		nil,
+	)
+}
+
+func (apiKeyHandler *APIKeyHandler) RevokeAPIKeyHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Revoking API key...")
+
+	key, err := apiKeyHandler.APIKeyService.RevokeAPIKey(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusNotFound,
+			"API key not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to revoke API key",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusOK,
+		"Successfully revoked API key",
+		key,
+		1,
+		nil,
+	)
+}
+
+func (apiKeyHandler *APIKeyHandler) SetAPIKeyBudgetHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Setting API key budget...")
+
+	var req newsArticle.LLMBudget
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	key, err := apiKeyHandler.APIKeyService.SetAPIKeyBudget(c.Request.Context(), c.Param("id"), req)
+	if errors.Is(err, services.ErrInvalidAPIKeyInput) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid budget",
+			err,
+		)
+		return
+	}
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusNotFound,
+			"API key not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to set API key budget",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusOK,
+		"Successfully set API key budget",
+		key,
+		1,
+		nil,
+	)
+}
@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+func (newsHandler *NewsHandler) AddBookmarkHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Adding bookmark...")
+
+	ctx := c.Request.Context()
+	var req newsArticle.BookmarkInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	bookmark, created, err := newsHandler.NewsService.AddBookmarkService(ctx, pathUserID(c), req)
+	if errors.Is(err, services.ErrInvalidBookmark) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid bookmark",
+			err,
+		)
+		return
+	}
+	if errors.Is(err, services.ErrArticleNotFound) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusNotFound,
+			"Article not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to save bookmark",
+			err,
+		)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if created {
+		statusCode = http.StatusCreated
+	}
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		statusCode,
+		"Successfully saved bookmark",
+		bookmark,
+		1,
+		map[string]interface{}{
+			"created": created, // false when the article was already in the collection
+		},
+	)
+}
+
+func (newsHandler *NewsHandler) RemoveBookmarkHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Removing bookmark...")
+
+	ctx := c.Request.Context()
+	removed, err := newsHandler.NewsService.RemoveBookmarkService(ctx, pathUserID(c), c.Param("article_id"), c.Query("collection"))
+	if errors.Is(err, services.ErrInvalidBookmark) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid bookmark",
+			err,
+		)
+		return
+	}
+	if errors.Is(err, services.ErrBookmarkNotFound) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusNotFound,
+			"Bookmark not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to remove bookmark",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully removed bookmark",
+		nil,
+		int(removed),
+		nil,
+	)
+}
+
+func (newsHandler *NewsHandler) ListBookmarksHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching bookmarks...")
+
+	ctx := c.Request.Context()
+	maxArticleLimit := parseArticleLimit(c, 20)
+
+	bookmarks, err := newsHandler.NewsService.ListBookmarksService(ctx, pathUserID(c), c.Query("collection"), maxArticleLimit)
+	if errors.Is(err, services.ErrInvalidBookmark) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid collection parameter",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve bookmarks",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved bookmarks",
+		bookmarks,
+		len(bookmarks),
+		nil,
+	)
+}
+
+func (newsHandler *NewsHandler) BookmarkCollectionsHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching bookmark collections...")
+
+	collections, err := newsHandler.NewsService.BookmarkCollectionsService(c.Request.Context(), pathUserID(c))
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve bookmark collections",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved bookmark collections",
+		collections,
+		len(collections),
+		nil,
+	)
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+func (newsHandler *NewsHandler) IngestEventsHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Ingesting user events...")
+	ctx := c.Request.Context()
+	var req struct {
+		Events []newsArticle.EventInput `json:"events"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	if len(req.Events) == 0 || len(req.Events) > services.MAX_EVENT_BATCH_SIZE {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			fmt.Sprintf("A batch must contain between 1 and %d events", services.MAX_EVENT_BATCH_SIZE),
+			nil,
+		)
+		return
+	}
+
+	results, err := newsHandler.NewsService.IngestEventsService(ctx, req.Events, reqctx.UserID(ctx))
+	if errors.Is(err, services.ErrEventQueueFull) {
+		c.Header("Retry-After", "1")
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusServiceUnavailable,
+			"Event queue is full, retry the batch later",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to store user events",
+			err,
+		)
+		return
+	}
+
+	counts := map[string]int{
+		newsArticle.EVENT_ACCEPTED:  0,
+		newsArticle.EVENT_REJECTED:  0,
+		newsArticle.EVENT_DUPLICATE: 0,
+		newsArticle.EVENT_DROPPED:   0,
+	}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully processed user events",
+		results,
+		len(results),
+		map[string]interface{}{
+			"accepted":  counts[newsArticle.EVENT_ACCEPTED],
+			"rejected":  counts[newsArticle.EVENT_REJECTED],
+			"duplicate": counts[newsArticle.EVENT_DUPLICATE],
+			"dropped":   counts[newsArticle.EVENT_DROPPED],
+		},
+	)
+}
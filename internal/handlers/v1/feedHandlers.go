@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+func (newsHandler *NewsHandler) FeedNewsHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching personalized feed...")
+
+	ctx := c.Request.Context()
+	userID, ok := newsHandler.queryUserID(c)
+	if !ok {
+		return
+	}
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	if userID == "" {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"User ID as 'user_id' or a bearer token is required",
+			nil,
+		)
+		return
+	}
+
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	// Invalid values mean false
+	blendAlsoRead, _ := strconv.ParseBool(c.Query("blend_also_read"))
+
+	newsArticles, strategy, err := newsHandler.NewsService.FeedNewsService(ctx, userID, maxArticleLimit, blendAlsoRead, filters)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve the personalized feed",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithMetadata(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved the personalized feed",
+		newsArticles,
+		len(newsArticles),
+		map[string]interface{}{
+			"strategy":        strategy, // "personalized", or "trending" for users without events
+			"blend_also_read": blendAlsoRead,
+		},
+	)
+}
+
+func (newsHandler *NewsHandler) AlsoReadHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching also read articles...")
+
+	ctx := c.Request.Context()
+	articleID := c.Param("id")
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	newsArticles, err := newsHandler.NewsService.AlsoReadService(ctx, articleID, maxArticleLimit, filters)
+	if errors.Is(err, services.ErrArticleNotFound) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusNotFound,
+			"Article not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve also read articles",
+			err,
+		)
+		return
+	}
+
+	newsResponse.Success(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved also read articles",
+		newsArticles,
+		len(newsArticles),
+	)
+}
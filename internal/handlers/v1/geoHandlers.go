@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+func (newsHandler *NewsHandler) WithinNewsHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching news articles within area...")
+	ctx := c.Request.Context()
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	// The area is either a 'bbox' query parameter or a GeoJSON Polygon/MultiPolygon in the body
+	var geometry utils.Geometry
+	if bboxStr := c.Query("bbox"); bboxStr != "" {
+		bbox, err := utils.ParseBBox(bboxStr)
+		if err != nil {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid bbox parameter",
+				err,
+			)
+			return
+		}
+		geometry = bbox.Geometry()
+	} else {
+		body, err := c.GetRawData()
+		if err != nil || len(body) == 0 {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Either 'bbox=minLon,minLat,maxLon,maxLat' or a GeoJSON polygon body is required",
+				err,
+			)
+			return
+		}
+
+		geometry, err = utils.ParseGeometry(body)
+		if err != nil {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid GeoJSON polygon",
+				err,
+			)
+			return
+		}
+	}
+
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	results, err := newsHandler.NewsService.WithinNewsService(ctx, geometry, maxArticleLimit, filters)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve news articles within area",
+			err,
+		)
+		return
+	}
+
+	newsResponse.Success(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved news articles within area",
+		results,
+		len(results),
+	)
+}
+
+func (newsHandler *NewsHandler) GeoClustersHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching news article clusters...")
+	ctx := c.Request.Context()
+
+	bbox, err := utils.ParseBBox(c.Query("bbox"))
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid or missing bbox parameter",
+			err,
+		)
+		return
+	}
+
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil || zoom < 0 || zoom > services.MAX_CLUSTER_ZOOM {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			fmt.Sprintf("Zoom parameter must be an integer between 0 and %d", services.MAX_CLUSTER_ZOOM),
+			err,
+		)
+		return
+	}
+
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	clusters, cellSizeDeg, err := newsHandler.NewsService.GeoClustersService(ctx, bbox, zoom, filters)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve news article clusters",
+			err,
+		)
+		return
+	}
+
+	totalArticles := 0
+	for _, cluster := range clusters {
+		totalArticles += cluster.Count
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved news article clusters",
+		clusters,
+		len(clusters),
+		map[string]interface{}{
+			"zoom":           zoom,
+			"cell_size_deg":  cellSizeDeg,
+			"total_articles": totalArticles,
+		},
+	)
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+)
+
+func (newsHandler *NewsHandler) ReadHistoryHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching read history...")
+
+	ctx := c.Request.Context()
+	maxArticleLimit := parseArticleLimit(c, 20)
+
+	articles, err := newsHandler.NewsService.ReadHistoryService(ctx, pathUserID(c), maxArticleLimit)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve read history",
+			err,
+		)
+		return
+	}
+
+	newsResponse.Success(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved read history",
+		articles,
+		len(articles),
+	)
+}
+
+// excludeSeen reads 'exclude_seen=true', which drops the articles in the read history of the user from the listing,
+// the user being 'user_id' or the bearer token's.
+// It sends a 400 or 500 response and returns false when that fails.
+func (newsHandler *NewsHandler) excludeSeen(c *gin.Context, filters *newsArticle.ArticleFilters) bool {
+	value := c.Query("exclude_seen")
+	if value == "" {
+		return true
+	}
+	userID, ok := newsHandler.queryUserID(c)
+	if !ok {
+		return false
+	}
+	excludeSeen, err := strconv.ParseBool(value)
+	if err != nil || (excludeSeen && userID == "") {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid exclude_seen parameter",
+			fmt.Errorf("'exclude_seen' must be true or false and requires 'user_id' or a bearer token"),
+		)
+		return false
+	}
+	if !excludeSeen {
+		return true
+	}
+
+	if err := newsHandler.NewsService.ExcludeSeenArticles(c.Request.Context(), userID, filters); err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve read history",
+			err,
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+func (apiKeyHandler *APIKeyHandler) LLMUsageHandler(c *gin.Context) {
+	apiKeyHandler.Logger.Debug("'Handler layer': Fetching LLM usage...")
+
+	// An empty api_key_id is the usage of requests without a key
+	var apiKeyID *string
+	if value, ok := c.GetQuery("api_key_id"); ok {
+		apiKeyID = &value
+	}
+
+	usages, err := apiKeyHandler.LLMUsageService.UsageReport(c.Request.Context(), c.Query("from"), c.Query("to"), apiKeyID)
+	if errors.Is(err, services.ErrInvalidUsageRange) {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid date range",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			apiKeyHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve LLM usage",
+			err,
+		)
+		return
+	}
+
+	var calls, promptTokens, completionTokens int64
+	costUSD := 0.0
+	for _, usage := range usages {
+		calls += usage.Calls
+		promptTokens += usage.PromptTokens
+		completionTokens += usage.CompletionTokens
+		costUSD += usage.CostUSD
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		apiKeyHandler.Logger,
+		http.StatusOK,
+		"Successfully retrieved LLM usage",
+		usages,
+		len(usages),
+		map[string]interface{}{
+			"calls":             calls,
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"cost_usd":          costUSD,
+		},
+	)
+}
@@ -1,11 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"strconv"
+	"time"
 	"log/slog"
 	"net/http"
 	"github.com/gin-gonic/gin"
 	// "github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
 	"github.com/shivam-cse/contextual-news-api/internal/services"
 )
@@ -29,12 +32,30 @@ func (newsHandler *NewsHandler) LatestNewsHandler(c *gin.Context) {
 	newsHandler.Logger.Debug("'Handler layer': Fetching latest news articles...")
 
 	ctx := c.Request.Context()
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	filters, err := parseArticleFilters(c)
 	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
 	}
 
-	newsArticles, err := newsHandler.NewsService.LatestNewsService(ctx, maxArticleLimit)
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	preferences, ok := newsHandler.userPreferences(c, &filters)
+	if !ok {
+		return
+	}
+
+	newsArticles, err := newsHandler.NewsService.LatestNewsService(ctx, maxArticleLimit, filters, preferences)
 
 	if err != nil {
 		newsResponse.Error(
@@ -62,11 +83,7 @@ func (newsHandler *NewsHandler) CategoryNewsHandler(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	category := c.Query("category")
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
-	
-	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
-	}
+	maxArticleLimit := parseArticleLimit(c, 5)
 
 	if category == "" {
 		newsResponse.Error(
@@ -79,7 +96,23 @@ func (newsHandler *NewsHandler) CategoryNewsHandler(c *gin.Context) {
 		return
 	}
 
-	results, err := newsHandler.NewsService.CategoryNewsService(ctx, category, maxArticleLimit)
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	results, err := newsHandler.NewsService.CategoryNewsService(ctx, category, maxArticleLimit, filters)
 	if err != nil {
 		newsResponse.Error(
 			c,
@@ -116,12 +149,25 @@ func (newsHandler *NewsHandler) ScoreNewsHandler(c *gin.Context) {
 		)
 		return
 	}
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	filters, err := parseArticleFilters(c)
 	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
 	}
 
-	results, err := newsHandler.NewsService.ScoreNewsService(ctx, threshold, maxArticleLimit)
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	results, err := newsHandler.NewsService.ScoreNewsService(ctx, threshold, maxArticleLimit, filters)
 	if err != nil {
 		newsResponse.Error(
 			c,
@@ -147,10 +193,7 @@ func (newsHandler *NewsHandler) SearchNewsHandler(c *gin.Context) {
 	newsHandler.Logger.Debug("'Handler layer': Fetching news articles by query...")
 	ctx := c.Request.Context()
 	query := c.Query("query")
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
-	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
-	}
+	maxArticleLimit := parseArticleLimit(c, 5)
 
 	if query == "" {
 		newsResponse.Error(
@@ -163,7 +206,40 @@ func (newsHandler *NewsHandler) SearchNewsHandler(c *gin.Context) {
 		return
 	}
 
-	results, err := newsHandler.NewsService.SearchNewsService(ctx, query, maxArticleLimit)
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	rank, err := parseNearbyRank(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid rank parameter",
+			err,
+		)
+		return
+	}
+
+	preferences, ok := newsHandler.userPreferences(c, &filters)
+	if !ok {
+		return
+	}
+
+	results, err := newsHandler.NewsService.SearchNewsService(ctx, query, maxArticleLimit, rank, filters, preferences)
 	if err != nil {
 		newsResponse.Error(
 			c,
@@ -189,10 +265,7 @@ func (newsHandler *NewsHandler) SourceNewsHandler(c *gin.Context) {
 	newsHandler.Logger.Debug("'Handler layer': Fetching news articles by source...")
 	ctx := c.Request.Context()
 	source := c.Query("source")
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
-	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
-	}
+	maxArticleLimit := parseArticleLimit(c, 5)
 
 	if source == "" {
 		newsResponse.Error(
@@ -205,7 +278,23 @@ func (newsHandler *NewsHandler) SourceNewsHandler(c *gin.Context) {
 		return
 	}
 
-	results, err := newsHandler.NewsService.SourceNewsService(ctx, source, maxArticleLimit)
+	filters, err := parseArticleFilters(c)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid filter parameter",
+			err,
+		)
+		return
+	}
+
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	results, err := newsHandler.NewsService.SourceNewsService(ctx, source, maxArticleLimit, filters)
 	if err != nil {
 		newsResponse.Error(
 			c,
@@ -237,10 +326,7 @@ func (newsHandler *NewsHandler) NearbyNewsHandler(c *gin.Context) {
 	// Default radius is 1km if not provided
 	radiusStr := c.DefaultQuery("radius", "1")
 
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
-	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
-	}
+	maxArticleLimit := parseArticleLimit(c, 5)
 
 	if latitudeStr == "" || longitudeStr == "" {
 		newsResponse.Error(
@@ -289,69 +375,75 @@ func (newsHandler *NewsHandler) NearbyNewsHandler(c *gin.Context) {
 		return
 	}
 
-	results, err := newsHandler.NewsService.NearbyNewsService(ctx, latitude, longitude, radius, maxArticleLimit)
+	filters, err := parseArticleFilters(c)
 	if err != nil {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
-			http.StatusInternalServerError,
-			"Failed to retrieve nearby news articles",
+			http.StatusBadRequest,
+			"Invalid filter parameter",
 			err,
 		)
 		return
 	}
 
-	newsResponse.Success(
-		c,
-		newsHandler.Logger,
-		http.StatusOK,
-		"Successfully retrieved nearby news articles",
-		results,
-		len(results),
-	)
-}
-
-func (newsHandler *NewsHandler) SimulateEventsHandler(c *gin.Context) {
-	newsHandler.Logger.Debug("'Handler layer': Simulating events...")
-	ctx := c.Request.Context()
-	var req struct {
-		UserID    string  `json:"user_id"`
-		ArticleID string  `json:"article_id"`
-		EventType string  `json:"event_type"`
-		Latitude  float64 `json:"latitude"`
-		Longitude float64 `json:"longitude"`
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	rank, err := parseNearbyRank(c)
+	if err != nil {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
 			http.StatusBadRequest,
-			"Invalid request payload",
+			"Invalid rank parameter",
 			err,
 		)
 		return
 	}
 
-	err := newsHandler.NewsService.SimulateEventsService(ctx, req.UserID, req.ArticleID, req.EventType, req.Latitude, req.Longitude)
+	// When fewer than 'min_results' articles are within the radius, the radius is widened step by step
+	minResults := 0
+	if minResultsStr := c.Query("min_results"); minResultsStr != "" {
+		minResults, err = strconv.Atoi(minResultsStr)
+		if err != nil || minResults < 0 {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid min_results parameter",
+				err,
+			)
+			return
+		}
+		// More results than the limit can never be returned
+		minResults = min(minResults, maxArticleLimit)
+	}
+
+	results, radiusUsed, err := newsHandler.NewsService.NearbyNewsService(ctx, latitude, longitude, radius, maxArticleLimit, minResults, rank, filters)
 	if err != nil {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
 			http.StatusInternalServerError,
-			"Failed to simulate events",
+			"Failed to retrieve nearby news articles",
 			err,
 		)
 		return
 	}
 
-	newsResponse.Success(
+	newsResponse.SuccessWithMetadata(
 		c,
 		newsHandler.Logger,
 		http.StatusOK,
-		"Successfully simulated events",
-		nil,
-		0,
+		"Successfully retrieved nearby news articles",
+		results,
+		len(results),
+		map[string]interface{}{
+			"requested_radius_km": radius,
+			"radius_km":           radiusUsed,
+		},
 	)
 }
 
@@ -359,63 +451,152 @@ func (newsHandler *NewsHandler) TrendingNewsHandler(c *gin.Context) {
 	newsHandler.Logger.Debug("'Handler layer': Fetching trending news articles...")
 	ctx := c.Request.Context()
 
-	radiusStr := c.DefaultQuery("radius", "1") // Default radius is 1km if not provided
+	radiusStr := c.DefaultQuery("radius", "25") // Default radius is 25km if not provided
 	latitudeStr := c.Query("lat")
 	longitudeStr := c.Query("lon")
-	maxArticleLimit, err := strconv.Atoi(c.Query("articleLimit"))
-
-	if err != nil {
-		maxArticleLimit = 5 // Default to 5 if maxArticleLimit is not provided or invalid
+	scope := c.DefaultQuery("scope", newsArticle.TRENDING_SCOPE_EVENTS)
+	maxArticleLimit := parseArticleLimit(c, 5)
+
+	// Without a location, trending is global
+	var location *newsArticle.TrendingLocation
+	if latitudeStr != "" || longitudeStr != "" {
+		if latitudeStr == "" || longitudeStr == "" {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Both latitude as 'lat' and longitude as 'lon' are required for local trending",
+				nil,
+			)
+			return
+		}
+
+		latitude, err := strconv.ParseFloat(latitudeStr, 64)
+		if err != nil {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid latitude parameter",
+				err,
+			)
+			return
+		}
+
+		longitude, err := strconv.ParseFloat(longitudeStr, 64)
+		if err != nil {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid longitude parameter",
+				err,
+			)
+			return
+		}
+
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid radius parameter",
+				err,
+			)
+			return
+		}
+
+		if !newsArticle.IsValidTrendingScope(scope) {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Scope parameter must be one of events, articles or both",
+				nil,
+			)
+			return
+		}
+
+		location = &newsArticle.TrendingLocation{
+			Scope:     scope,
+			Latitude:  latitude,
+			Longitude: longitude,
+			RadiusKm:  radius,
+		}
 	}
 
-	if latitudeStr == "" || longitudeStr == "" {
+	filters, err := parseArticleFilters(c)
+	if err != nil {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
 			http.StatusBadRequest,
-			"Latitude as 'lat' and longitude as 'lon' parameters are required",
-			nil,
+			"Invalid filter parameter",
+			err,
 		)
 		return
 	}
 
-	latitude, err := strconv.ParseFloat(latitudeStr, 64)
-	if err != nil {
+	if !newsHandler.excludeSeen(c, &filters) {
+		return
+	}
+
+	window := c.DefaultQuery("window", newsArticle.DEFAULT_TRENDING_WINDOW)
+	if _, ok := newsArticle.TRENDING_WINDOWS[window]; !ok {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
 			http.StatusBadRequest,
-			"Invalid latitude parameter",
-			err,
+			"Window parameter must be one of 1h, 24h or 7d",
+			nil,
 		)
 		return
 	}
 
-	longitude, err := strconv.ParseFloat(longitudeStr, 64)
+	// 'as_of' serves the snapshot built at or before that time, a plain date means the end of that day
+	var asOf *time.Time
+	asOfValue, err := parseDateParam(c, "as_of", true)
 	if err != nil {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
 			http.StatusBadRequest,
-			"Invalid longitude parameter",
+			"Invalid as_of parameter",
 			err,
 		)
 		return
 	}
+	if !asOfValue.IsZero() {
+		if location != nil && location.Scope != newsArticle.TRENDING_SCOPE_EVENTS {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Historical trending with as_of only supports the events scope",
+				nil,
+			)
+			return
+		}
+		asOf = &asOfValue
+	}
 
-	radius, err := strconv.ParseFloat(radiusStr, 64)
-	if err != nil {
+	preferences, ok := newsHandler.userPreferences(c, &filters)
+	if !ok {
+		return
+	}
+
+	results, source, err := newsHandler.NewsService.TrendingNewsService(ctx, maxArticleLimit, window, location, filters, asOf, preferences)
+	if errors.Is(err, services.ErrTrendingSnapshotNotFound) {
 		newsResponse.Error(
 			c,
 			newsHandler.Logger,
-			http.StatusBadRequest,
-			"Invalid radius parameter",
+			http.StatusNotFound,
+			"No trending snapshot found at or before as_of",
 			err,
 		)
 		return
 	}
-
-	results, err := newsHandler.NewsService.TrendingNewsService(ctx, maxArticleLimit, latitude, longitude, radius)
 	if err != nil {
 		newsResponse.Error(
 			c,
@@ -427,12 +608,20 @@ func (newsHandler *NewsHandler) TrendingNewsHandler(c *gin.Context) {
 		return
 	}
 
-	newsResponse.Success(
+	newsResponse.SuccessWithMetadata(
 		c,
 		newsHandler.Logger,
 		http.StatusOK,
 		"Successfully retrieved trending news articles",
 		results,
 		len(results),
+		map[string]interface{}{
+			"window":               window,
+			"location":             location, // null for global trending
+			"source":               source.Source,
+			"snapshot_as_of":       source.AsOf,       // null when computed live
+			"snapshot_age_seconds": source.AgeSeconds, // null when computed live
+			"snapshot_cells":       source.Cells,
+		},
 	)
 }
\ No newline at end of file
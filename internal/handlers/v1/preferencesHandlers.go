@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+func (newsHandler *NewsHandler) GetPreferencesHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Fetching user preferences...")
+
+	preferences, err := newsHandler.NewsService.GetUserPreferencesService(c.Request.Context(), pathUserID(c))
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully retrieved user preferences")
+}
+
+// ReplacePreferencesHandler replaces all the preferences of the user, e.g. with the interests picked at onboarding.
+func (newsHandler *NewsHandler) ReplacePreferencesHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Replacing user preferences...")
+
+	var req newsArticle.UserPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	preferences, err := newsHandler.NewsService.ReplaceUserPreferencesService(c.Request.Context(), pathUserID(c), req)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully saved user preferences")
+}
+
+func (newsHandler *NewsHandler) FollowHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Following...")
+
+	preferences, err := newsHandler.NewsService.FollowService(c.Request.Context(), pathUserID(c), c.Param("kind"), c.Param("value"), true)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully followed")
+}
+
+func (newsHandler *NewsHandler) UnfollowHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Unfollowing...")
+
+	preferences, err := newsHandler.NewsService.FollowService(c.Request.Context(), pathUserID(c), c.Param("kind"), c.Param("value"), false)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully unfollowed")
+}
+
+func (newsHandler *NewsHandler) FollowPlaceHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Following place...")
+
+	var place newsArticle.SavedPlace
+	if err := c.ShouldBindJSON(&place); err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid request payload",
+			err,
+		)
+		return
+	}
+
+	preferences, err := newsHandler.NewsService.FollowPlaceService(c.Request.Context(), pathUserID(c), place)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully followed place")
+}
+
+func (newsHandler *NewsHandler) MuteHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Muting...")
+
+	preferences, err := newsHandler.NewsService.MuteService(c.Request.Context(), pathUserID(c), c.Param("kind"), c.Param("value"), true)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully muted")
+}
+
+func (newsHandler *NewsHandler) UnmuteHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Unmuting...")
+
+	preferences, err := newsHandler.NewsService.MuteService(c.Request.Context(), pathUserID(c), c.Param("kind"), c.Param("value"), false)
+	newsHandler.preferencesResponse(c, preferences, err, "Successfully unmuted")
+}
+
+func (newsHandler *NewsHandler) preferencesResponse(
+	c *gin.Context,
+	preferences *newsArticle.UserPreferences,
+	err error,
+	message string,
+) {
+	if errors.Is(err, services.ErrInvalidPreference) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"Invalid preference",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to update user preferences",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		message,
+		preferences,
+		1,
+		nil,
+	)
+}
+
+// userPreferences applies the preferences of the optional 'user_id', or the bearer token's user, to the filters:
+// their mutes always, and with 'following=true' only the articles matching their follows.
+// It sends a 400 or 500 response and returns false when that fails.
+func (newsHandler *NewsHandler) userPreferences(c *gin.Context, filters *newsArticle.ArticleFilters) (*newsArticle.UserPreferences, bool) {
+	userID, ok := newsHandler.queryUserID(c)
+	if !ok {
+		return nil, false
+	}
+	following := false
+	if value := c.Query("following"); value != "" {
+		var err error
+		following, err = strconv.ParseBool(value)
+		if err != nil || (following && userID == "") {
+			newsResponse.Error(
+				c,
+				newsHandler.Logger,
+				http.StatusBadRequest,
+				"Invalid following parameter",
+				fmt.Errorf("'following' must be true or false and requires 'user_id' or a bearer token"),
+			)
+			return nil, false
+		}
+	}
+
+	preferences, err := newsHandler.NewsService.ApplyUserPreferences(c.Request.Context(), userID, following, filters)
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusInternalServerError,
+			"Failed to retrieve user preferences",
+			err,
+		)
+		return nil, false
+	}
+	return preferences, true
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+)
+
+// parseArticleFilters reads the optional filters shared by the listing endpoints.
+// e.g. ?country=IN&region=Jharkhand&category=sports&source=reuters&min_score=0.5&from=2025-03-20&to=2025-03-27
+func parseArticleFilters(c *gin.Context) (newsArticle.ArticleFilters, error) {
+	filters := newsArticle.ArticleFilters{
+		CountryCode: c.Query("country"),
+		Region:      c.Query("region"),
+		Category:    c.Query("category"),
+		Source:      c.Query("source"),
+	}
+
+	if minScoreStr := c.Query("min_score"); minScoreStr != "" {
+		minScore, err := strconv.ParseFloat(minScoreStr, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			return filters, fmt.Errorf("'min_score' must be a float between 0 and 1")
+		}
+		filters.MinScore = minScore
+	}
+
+	var err error
+	if filters.PublishedFrom, err = parseDateParam(c, "from", false); err != nil {
+		return filters, err
+	}
+	if filters.PublishedTo, err = parseDateParam(c, "to", true); err != nil {
+		return filters, err
+	}
+	if !filters.PublishedFrom.IsZero() && !filters.PublishedTo.IsZero() && filters.PublishedTo.Before(filters.PublishedFrom) {
+		return filters, fmt.Errorf("'to' must not be before 'from'")
+	}
+
+	return filters, nil
+}
+
+// parseDateParam accepts either RFC 3339 ("2025-03-26T04:46:55Z") or a plain date ("2025-03-26").
+// With 'endOfDay' a plain date covers the whole day, so "to=2025-03-26" includes articles from that day.
+// A missing parameter gives the zero time.
+func parseDateParam(c *gin.Context, name string, endOfDay bool) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		if endOfDay {
+			date = date.Add(24*time.Hour - time.Second)
+		}
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' must be a date (2006-01-02) or an RFC 3339 timestamp", name)
+}
+
+// parseArticleLimit reads 'articleLimit', the number of articles a listing returns. It must be a positive
+// integer: a missing, invalid, zero or negative value gives 'defaultLimit', so a listing is never unlimited.
+func parseArticleLimit(c *gin.Context, defaultLimit int) int {
+	articleLimit, err := strconv.Atoi(c.Query("articleLimit"))
+	if err != nil || articleLimit <= 0 {
+		return defaultLimit
+	}
+	return articleLimit
+}
+
+// parseNearbyRank reads 'rank=distance|recency|blend', defaulting to 'distance'.
+func parseNearbyRank(c *gin.Context) (string, error) {
+	rank := c.DefaultQuery("rank", newsArticle.NEARBY_RANK_DISTANCE)
+	if !newsArticle.IsValidNearbyRank(rank) {
+		return "", fmt.Errorf("'rank' must be one of distance, recency or blend")
+	}
+	return rank, nil
+}
@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+// pathUserID returns the user of the '/users/:user_id' routes. With a bearer token it is the token's subject,
+// which the JWT middleware already checked against the path or put in place of "me".
+func pathUserID(c *gin.Context) string {
+	if userID := reqctx.UserID(c.Request.Context()); userID != "" {
+		return userID
+	}
+	return c.Param("user_id")
+}
+
+// queryUserID returns the user of the optional 'user_id' parameter, or the token's subject with a bearer token.
+// It sends a 400 response and returns false when both are given, the token then being the only source of the user.
+func (newsHandler *NewsHandler) queryUserID(c *gin.Context) (string, bool) {
+	userID := reqctx.UserID(c.Request.Context())
+	if userID == "" {
+		return c.Query("user_id"), true
+	}
+	if c.Query("user_id") != "" {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadRequest,
+			"The user is taken from the bearer token, 'user_id' must not be sent",
+			nil,
+		)
+		return "", false
+	}
+	return userID, true
+}
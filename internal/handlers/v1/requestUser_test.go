@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/middleware"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/jwt"
+)
+
+var testJWTSecret = []byte("test-secret-of-at-least-32-bytes!!")
+
+// noEventPublisher fails the test when an event would be published.
+type noEventPublisher struct {
+	t *testing.T
+}
+
+func (publisher noEventPublisher) Publish(events []newsArticle.UserEvent) ([]string, error) {
+	if len(events) > 0 {
+		publisher.t.Errorf("published %d events, want none", len(events))
+	}
+	return []string{}, nil
+}
+
+// newTestRouter serves the handlers behind the JWT middleware. The service has no database,
+// so the requests must be refused before the handlers reach it.
+func newTestRouter(t *testing.T) (*gin.Engine, string) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	verifier, err := jwt.NewVerifier(jwt.VerifierOptions{HMACSecret: testJWTSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtAuth := middleware.NewJWTAuth(verifier, logger, false)
+	newsService := &services.NewsService{
+		DbInterface:    &dbInterface.NewsDbInterface{Logger: logger},
+		Logger:         logger,
+		EventPublisher: noEventPublisher{t: t},
+	}
+	newsHandler := NewNewsHandler(newsService, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/news/feed", jwtAuth.Optional(), newsHandler.FeedNewsHandler)
+	router.POST("/events", jwtAuth.UserScoped(), newsHandler.IngestEventsHandler)
+
+	token, err := jwt.SignHS256(jwt.Claims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()}, testJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router, token
+}
+
+func TestQueryUserIDWithTokenIsRefused(t *testing.T) {
+	router, token := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/news/feed?user_id=user-2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body)
+	}
+}
+
+func TestBodyUserIDWithTokenIsRejected(t *testing.T) {
+	router, token := newTestRouter(t)
+
+	body := `{"events": [
+		{"client_event_id": "event-1", "event_type": "view", "article_id": "article-1", "user_id": "user-2"},
+		{"client_event_id": "event-2", "event_type": "click", "article_id": "article-1", "user_id": "user-1"}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	var response struct {
+		Data []newsArticle.EventResult `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 2 {
+		t.Fatalf("got %d results, want 2", len(response.Data))
+	}
+	// Even the token's own user must not be sent in the body
+	for _, result := range response.Data {
+		if result.Status != newsArticle.EVENT_REJECTED {
+			t.Errorf("event %s: status = %q, want %q", result.ClientEventID, result.Status, newsArticle.EVENT_REJECTED)
+		}
+	}
+}
@@ -5,15 +5,28 @@ import (
 
 	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/middleware"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
 )
 
 var DefaultTimeoutDuration = 600*time.Second
 
-func RegisterRoutes(router *gin.Engine, newsHandlers *NewsHandler) {
+func RegisterRoutes(
+	router *gin.Engine,
+	newsHandlers *NewsHandler,
+	apiKeyHandlers *APIKeyHandler,
+	apiKeyAuth *middleware.APIKeyAuth,
+	jwtAuth *middleware.JWTAuth,
+	rateLimiter *middleware.RateLimiter,
+	llmBudgetGuard *middleware.LLMBudgetGuard,
+) {
+	logger := newsHandlers.Logger
+
 	api := router.Group("/api/v1")
 	{
-		news := api.Group("/news")
+		// The listings take summarize=<none|eager|lazy|cached_only>
+		news := api.Group("/news", apiKeyAuth.Require(newsArticle.SCOPE_READ), rateLimiter.Limit(middleware.RATE_LIMIT_NEWS), llmBudgetGuard.Check(), jwtAuth.Optional(), middleware.SummaryMode(logger))
 		{
 			// GET /api/v1/news/latest?articleLimit=<limit>
 			news.GET("/latest", timeout.New(
@@ -34,7 +47,7 @@ func RegisterRoutes(router *gin.Engine, newsHandlers *NewsHandler) {
 				), newsHandlers.ScoreNewsHandler)
 
 			// GET /api/v1/news/search?query=<query>&articleLimit=<limit>
-			news.GET("/search", timeout.New(
+			news.GET("/search", rateLimiter.Limit(middleware.RATE_LIMIT_LLM), timeout.New(
 					timeout.WithTimeout(DefaultTimeoutDuration),
 					timeout.WithResponse(newsResponse.TimeOut),
 				), newsHandlers.SearchNewsHandler)
@@ -51,18 +64,168 @@ func RegisterRoutes(router *gin.Engine, newsHandlers *NewsHandler) {
 					timeout.WithResponse(newsResponse.TimeOut),
 				), newsHandlers.NearbyNewsHandler)
 
+			// GET /api/v1/news/within?bbox=<minLon,minLat,maxLon,maxLat>&articleLimit=<limit>
+			// or a GeoJSON Polygon/MultiPolygon as the body (POST is accepted for clients which can't send a GET body)
+			news.GET("/within", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.WithinNewsHandler)
+			news.POST("/within", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.WithinNewsHandler)
+
+			// GET /api/v1/news/geo/clusters?bbox=<minLon,minLat,maxLon,maxLat>&zoom=<0-20>&category=<category>&from=<date>&to=<date>
+			news.GET("/geo/clusters", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.GeoClustersHandler)
+
 			// GET /api/v1/news/trending?lat=<latitude>&long=<longitude>&articleLimit=<limit>
 			news.GET("/trending", timeout.New(
 					timeout.WithTimeout(DefaultTimeoutDuration),
 					timeout.WithResponse(newsResponse.TimeOut),
 				), newsHandlers.TrendingNewsHandler)
 
-			// GET /api/v1/news/trending
-			news.POST("/events/simulate", timeout.New(
+			// GET /api/v1/news/feed?user_id=<user id>&articleLimit=<limit>&blend_also_read=<true|false>
+			// the user being the bearer token's instead of 'user_id' when there is one
+			news.GET("/feed", jwtAuth.UserScoped(), timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.FeedNewsHandler)
+
+			// POST /api/v1/news/<article id>/summary, generating the summary unless it is stored
+			news.POST("/:id/summary", rateLimiter.Limit(middleware.RATE_LIMIT_LLM), timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.SummarizeArticleHandler)
+
+			// GET /api/v1/news/<article id>/also-read?articleLimit=<limit>
+			news.GET("/:id/also-read", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.AlsoReadHandler)
+		}
+
+		// POST /api/v1/events with a JSON body {"events": [...]}, without user_id in the events when there is a bearer token
+		api.POST("/events", apiKeyAuth.Require(newsArticle.SCOPE_EVENTS_WRITE), rateLimiter.Limit(middleware.RATE_LIMIT_EVENTS), jwtAuth.UserScoped(), timeout.New(
+				timeout.WithTimeout(DefaultTimeoutDuration),
+				timeout.WithResponse(newsResponse.TimeOut),
+			), newsHandlers.IngestEventsHandler)
+
+		// <user id> is "me" for the user of the bearer token. Reading needs the read scope and changing users:write.
+		users := api.Group("/users/:user_id", apiKeyAuth.Require(newsArticle.SCOPE_READ), rateLimiter.Limit(middleware.RATE_LIMIT_USERS), jwtAuth.UserScoped())
+		{
+			// GET /api/v1/users/<user id>/preferences
+			users.GET("/preferences", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.GetPreferencesHandler)
+
+			// GET /api/v1/users/<user id>/bookmarks?collection=<name>&articleLimit=<limit>
+			users.GET("/bookmarks", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.ListBookmarksHandler)
+
+			// GET /api/v1/users/<user id>/bookmarks/collections
+			users.GET("/bookmarks/collections", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.BookmarkCollectionsHandler)
+
+			// GET /api/v1/users/<user id>/history?articleLimit=<limit>
+			users.GET("/history", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.ReadHistoryHandler)
+		}
+
+		userWrites := api.Group("/users/:user_id", apiKeyAuth.Require(newsArticle.SCOPE_USERS_WRITE), rateLimiter.Limit(middleware.RATE_LIMIT_USERS), jwtAuth.UserScoped())
+		{
+			// PUT /api/v1/users/<user id>/preferences with all the follows and mutes as the body, e.g. from onboarding
+			userWrites.PUT("/preferences", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.ReplacePreferencesHandler)
+
+			// POST /api/v1/users/<user id>/follows/places with a JSON body {"name", "latitude", "longitude", "radius_km"}
+			userWrites.POST("/follows/places", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.FollowPlaceHandler)
+
+			// PUT|DELETE /api/v1/users/<user id>/follows/<categories|sources>/<value>
+			// DELETE /api/v1/users/<user id>/follows/places/<place name>
+			userWrites.PUT("/follows/:kind/:value", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.FollowHandler)
+			userWrites.DELETE("/follows/:kind/:value", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.UnfollowHandler)
+
+			// PUT|DELETE /api/v1/users/<user id>/mutes/<sources|keywords>/<value>
+			userWrites.PUT("/mutes/:kind/:value", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.MuteHandler)
+			userWrites.DELETE("/mutes/:kind/:value", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.UnmuteHandler)
+
+			// DELETE /api/v1/users/<user id>/bookmarks/<article id>?collection=<name>, from all collections without one
+			userWrites.DELETE("/bookmarks/:article_id", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), newsHandlers.RemoveBookmarkHandler)
+		}
+
+		// POST /api/v1/users/<user id>/bookmarks with a JSON body {"article_id", "collection"}
+		// Bookmarking records a bookmark event, so it also needs events:write
+		api.POST("/users/:user_id/bookmarks", apiKeyAuth.Require(newsArticle.SCOPE_USERS_WRITE, newsArticle.SCOPE_EVENTS_WRITE), rateLimiter.Limit(middleware.RATE_LIMIT_USERS), jwtAuth.UserScoped(), timeout.New(
+				timeout.WithTimeout(DefaultTimeoutDuration),
+				timeout.WithResponse(newsResponse.TimeOut),
+			), newsHandlers.AddBookmarkHandler)
+
+		admin := api.Group("/admin/api-keys", apiKeyAuth.Require(newsArticle.SCOPE_ADMIN), rateLimiter.Limit(middleware.RATE_LIMIT_ADMIN))
+		{
+			// GET /api/v1/admin/api-keys
+			// POST /api/v1/admin/api-keys with a JSON body {"name", "scopes", "expires_at"}
+			admin.GET("", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), apiKeyHandlers.ListAPIKeysHandler)
+			admin.POST("", timeout.New(
 					timeout.WithTimeout(DefaultTimeoutDuration),
 					timeout.WithResponse(newsResponse.TimeOut),
-				), newsHandlers.SimulateEventsHandler)
+				), apiKeyHandlers.CreateAPIKeyHandler)
+
+			// POST /api/v1/admin/api-keys/<key id>/rotate
+			admin.POST("/:id/rotate", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), apiKeyHandlers.RotateAPIKeyHandler)
+
+			// DELETE /api/v1/admin/api-keys/<key id>
+			admin.DELETE("/:id", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), apiKeyHandlers.RevokeAPIKeyHandler)
+
+			// PUT /api/v1/admin/api-keys/<key id>/budget with a JSON body {"daily_usd", "monthly_usd"}
+			admin.PUT("/:id/budget", timeout.New(
+					timeout.WithTimeout(DefaultTimeoutDuration),
+					timeout.WithResponse(newsResponse.TimeOut),
+				), apiKeyHandlers.SetAPIKeyBudgetHandler)
 		}
+
+		// GET /api/v1/admin/llm-usage?from=<date>&to=<date>&api_key_id=<key id>
+		api.GET("/admin/llm-usage", apiKeyAuth.Require(newsArticle.SCOPE_ADMIN), rateLimiter.Limit(middleware.RATE_LIMIT_ADMIN), timeout.New(
+				timeout.WithTimeout(DefaultTimeoutDuration),
+				timeout.WithResponse(newsResponse.TimeOut),
+			), apiKeyHandlers.LLMUsageHandler)
 	}
 }
-
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+func (newsHandler *NewsHandler) SummarizeArticleHandler(c *gin.Context) {
+	newsHandler.Logger.Debug("'Handler layer': Summarizing article...")
+
+	ctx := c.Request.Context()
+	articleID := c.Param("id")
+
+	summary, err := newsHandler.NewsService.SummarizeArticleService(ctx, articleID)
+	if errors.Is(err, services.ErrArticleNotFound) {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusNotFound,
+			"Article not found",
+			err,
+		)
+		return
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			newsHandler.Logger,
+			http.StatusBadGateway,
+			"Failed to summarize the article",
+			err,
+		)
+		return
+	}
+
+	newsResponse.SuccessWithData(
+		c,
+		newsHandler.Logger,
+		http.StatusOK,
+		"Successfully summarized the article",
+		summary,
+		1,
+		nil,
+	)
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+// API_KEY_HEADER is the request header carrying the client's API key.
+const API_KEY_HEADER = "X-API-Key"
+
+// APIKeyAuth authenticates requests by their API key.
+type APIKeyAuth struct {
+	APIKeyService *services.APIKeyService
+	Logger        *slog.Logger
+	// Enabled is false to leave the routes open, e.g. in local development
+	Enabled bool
+}
+
+func NewAPIKeyAuth(apiKeyService *services.APIKeyService, logger *slog.Logger, enabled bool) *APIKeyAuth {
+	return &APIKeyAuth{
+		APIKeyService: apiKeyService,
+		Logger:        logger,
+		Enabled:       enabled,
+	}
+}
+
+// Require refuses the requests without a valid API key with 401, and with a key lacking one of the scopes with 403.
+// The ID of the key is added to the request context, see reqctx.APIKeyID.
+func (auth *APIKeyAuth) Require(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.Enabled {
+			c.Next()
+			return
+		}
+
+		rawKey := c.GetHeader(API_KEY_HEADER)
+		if rawKey == "" {
+			newsResponse.Error(
+				c,
+				auth.Logger,
+				http.StatusUnauthorized,
+				"An API key is required in the X-API-Key header",
+				nil,
+			)
+			return
+		}
+
+		key, err := auth.APIKeyService.Authenticate(c.Request.Context(), rawKey)
+		if errors.Is(err, services.ErrInvalidAPIKey) || errors.Is(err, services.ErrAPIKeyExpired) {
+			newsResponse.Error(
+				c,
+				auth.Logger,
+				http.StatusUnauthorized,
+				"Invalid API key",
+				err,
+			)
+			return
+		}
+		if err != nil {
+			newsResponse.Error(
+				c,
+				auth.Logger,
+				http.StatusInternalServerError,
+				"Failed to check the API key",
+				err,
+			)
+			return
+		}
+
+		c.Request = c.Request.WithContext(reqctx.WithAPIKeyID(c.Request.Context(), key.ID))
+		for _, scope := range scopes {
+			if !key.HasScope(scope) {
+				newsResponse.Error(
+					c,
+					auth.Logger,
+					http.StatusForbidden,
+					"The API key is not allowed to use this endpoint",
+					fmt.Errorf("the %q scope is required", scope),
+				)
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/pkg/jwt"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+// ME_USER_ID stands for the user of the bearer token in the '/users/:user_id' routes.
+const ME_USER_ID = "me"
+
+// JWTAuth authenticates end users by the bearer JWT in the Authorization header.
+// The token's subject is the user ID, which is added to the request context, see reqctx.UserID.
+type JWTAuth struct {
+	// Verifier is nil when no HMAC secret or JWKS is configured, bearer tokens are then refused
+	Verifier *jwt.Verifier
+	Logger   *slog.Logger
+	// Required is true to refuse the user-scoped requests without a token
+	Required bool
+}
+
+func NewJWTAuth(verifier *jwt.Verifier, logger *slog.Logger, required bool) *JWTAuth {
+	return &JWTAuth{
+		Verifier: verifier,
+		Logger:   logger,
+		Required: required,
+	}
+}
+
+// Optional authenticates the request when it has a bearer token, refusing an invalid token with 401.
+// Requests without one go through as they are.
+func (auth *JWTAuth) Optional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth.authenticate(c) {
+			c.Next()
+		}
+	}
+}
+
+// UserScoped authenticates like Optional, and also refuses the requests without a token with 401
+// when tokens are required. On the '/users/:user_id' routes, a token for another user is refused with 403,
+// and ME_USER_ID as the user requires a token.
+func (auth *JWTAuth) UserScoped() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The route may sit in a group which already authenticated it
+		if reqctx.UserID(c.Request.Context()) == "" && !auth.authenticate(c) {
+			return
+		}
+
+		userID := reqctx.UserID(c.Request.Context())
+		pathUserID := c.Param("user_id")
+		if userID == "" && (auth.Required || pathUserID == ME_USER_ID) {
+			newsResponse.Error(
+				c,
+				auth.Logger,
+				http.StatusUnauthorized,
+				"A bearer token is required in the Authorization header",
+				nil,
+			)
+			return
+		}
+		if userID != "" && pathUserID != "" && pathUserID != ME_USER_ID && pathUserID != userID {
+			newsResponse.Error(
+				c,
+				auth.Logger,
+				http.StatusForbidden,
+				"The bearer token is not allowed to access this user",
+				fmt.Errorf("the token is for another user than %q", pathUserID),
+			)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate verifies the bearer token of the request, if any. It sends a 401 response and returns false
+// when the token is invalid.
+func (auth *JWTAuth) authenticate(c *gin.Context) bool {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return true
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		newsResponse.Error(
+			c,
+			auth.Logger,
+			http.StatusUnauthorized,
+			"Invalid Authorization header, expected 'Bearer <token>'",
+			nil,
+		)
+		return false
+	}
+	if auth.Verifier == nil {
+		newsResponse.Error(
+			c,
+			auth.Logger,
+			http.StatusUnauthorized,
+			"Bearer tokens are not accepted",
+			errors.New("no JWT secret or JWKS is configured"),
+		)
+		return false
+	}
+
+	claims, err := auth.Verifier.Verify(strings.TrimSpace(token))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		newsResponse.Error(
+			c,
+			auth.Logger,
+			http.StatusUnauthorized,
+			"The bearer token has expired",
+			err,
+		)
+		return false
+	}
+	if err != nil {
+		newsResponse.Error(
+			c,
+			auth.Logger,
+			http.StatusUnauthorized,
+			"Invalid bearer token",
+			err,
+		)
+		return false
+	}
+
+	c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), claims.Subject))
+	return true
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/pkg/jwt"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+var testJWTSecret = []byte("test-secret-of-at-least-32-bytes!!")
+
+func newTestJWTAuth(t *testing.T, required bool) *JWTAuth {
+	t.Helper()
+	verifier, err := jwt.NewVerifier(jwt.VerifierOptions{HMACSecret: testJWTSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewJWTAuth(verifier, slog.New(slog.NewTextHandler(io.Discard, nil)), required)
+}
+
+func testToken(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.SignHS256(claims, testJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// newUserScopedRouter serves the user of the request on a '/users/:user_id' route guarded by UserScoped.
+func newUserScopedRouter(auth *JWTAuth) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/users/:user_id/preferences", auth.UserScoped(), func(c *gin.Context) {
+		c.String(http.StatusOK, reqctx.UserID(c.Request.Context()))
+	})
+	return router
+}
+
+func serve(router *gin.Engine, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestUserScoped(t *testing.T) {
+	userToken := testToken(t, jwt.Claims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	expiredToken := testToken(t, jwt.Claims{Subject: "user-1", ExpiresAt: time.Now().Add(-time.Hour).Unix()})
+
+	tests := []struct {
+		name       string
+		required   bool
+		path       string
+		token      string
+		wantStatus int
+		wantUserID string
+	}{
+		{"own user", false, "/users/user-1/preferences", userToken, http.StatusOK, "user-1"},
+		{"me", false, "/users/me/preferences", userToken, http.StatusOK, "user-1"},
+		{"another user", false, "/users/user-2/preferences", userToken, http.StatusForbidden, ""},
+		{"me without a token", false, "/users/me/preferences", "", http.StatusUnauthorized, ""},
+		{"user without a token", false, "/users/user-2/preferences", "", http.StatusOK, ""},
+		{"user without a required token", true, "/users/user-2/preferences", "", http.StatusUnauthorized, ""},
+		{"invalid token", false, "/users/user-1/preferences", userToken + "x", http.StatusUnauthorized, ""},
+		{"expired token", false, "/users/user-1/preferences", expiredToken, http.StatusUnauthorized, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := serve(newUserScopedRouter(newTestJWTAuth(t, test.required)), test.path, test.token)
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if test.wantStatus == http.StatusOK && recorder.Body.String() != test.wantUserID {
+				t.Errorf("user = %q, want %q", recorder.Body.String(), test.wantUserID)
+			}
+		})
+	}
+}
+
+func TestUserScopedWithoutVerifier(t *testing.T) {
+	auth := NewJWTAuth(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), false)
+	token := testToken(t, jwt.Claims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+	if recorder := serve(newUserScopedRouter(auth), "/users/user-1/preferences", token); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOptionalRefusesMalformedAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/news/latest", newTestJWTAuth(t, false).Optional(), func(c *gin.Context) {
+		c.String(http.StatusOK, reqctx.UserID(c.Request.Context()))
+	})
+
+	for _, header := range []string{"Basic dXNlcjpwYXNz", "Bearer", "Bearer  ", testToken(t, jwt.Claims{Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()})} {
+		req := httptest.NewRequest(http.MethodGet, "/news/latest", nil)
+		req.Header.Set("Authorization", header)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want %d", header, recorder.Code, http.StatusUnauthorized)
+		}
+	}
+}
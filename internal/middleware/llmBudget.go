@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+// LLM_BUDGET_HEADER names the exceeded budget, "daily" or "monthly", on responses served without LLM calls.
+const LLM_BUDGET_HEADER = "X-LLM-Budget-Exceeded"
+
+// LLMBudgetGuard stops the LLM calls of the requests whose API key is over its LLM budget.
+type LLMBudgetGuard struct {
+	UsageService *services.LLMUsageService
+	Logger       *slog.Logger
+	// Mode is newsArticle.LLM_BUDGET_SKIP_SUMMARIES or newsArticle.LLM_BUDGET_REJECT
+	Mode string
+}
+
+func NewLLMBudgetGuard(usageService *services.LLMUsageService, logger *slog.Logger, mode string) *LLMBudgetGuard {
+	return &LLMBudgetGuard{
+		UsageService: usageService,
+		Logger:       logger,
+		Mode:         mode,
+	}
+}
+
+// Check refuses the requests of a key over budget with 402 in reject mode, and otherwise serves them
+// without LLM calls, naming the exceeded budget in the LLM_BUDGET_HEADER header.
+// Requests without an API key have no budget. It must run after the API key authentication.
+func (guard *LLMBudgetGuard) Check() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKeyID := reqctx.APIKeyID(c.Request.Context())
+		if apiKeyID == "" {
+			c.Next()
+			return
+		}
+
+		exceeded, err := guard.UsageService.ExceededBudget(c.Request.Context(), apiKeyID)
+		if err != nil {
+			guard.Logger.Warn("Failed to check LLM budget, letting the request through", "api_key_id", apiKeyID, "error", err)
+			c.Next()
+			return
+		}
+		if exceeded == "" {
+			c.Next()
+			return
+		}
+
+		if guard.Mode == newsArticle.LLM_BUDGET_REJECT {
+			newsResponse.Error(
+				c,
+				guard.Logger,
+				http.StatusPaymentRequired,
+				"The API key is over its LLM budget",
+				fmt.Errorf("the %s LLM budget is used up", exceeded),
+			)
+			return
+		}
+		c.Header(LLM_BUDGET_HEADER, exceeded)
+		c.Request = c.Request.WithContext(services.WithoutLLM(c.Request.Context()))
+		c.Next()
+	}
+}
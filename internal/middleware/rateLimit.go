@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+// Route groups of the rate limiter, each with its own bucket per client.
+// RATE_LIMIT_LLM is the stricter bucket of the routes which make several LLM calls per request,
+// taken on top of their group's. RATE_LIMIT_AUTH is the bucket of the refused credentials of an IP address.
+const (
+	RATE_LIMIT_NEWS   = "news"
+	RATE_LIMIT_LLM    = "llm"
+	RATE_LIMIT_EVENTS = "events"
+	RATE_LIMIT_USERS  = "users"
+	RATE_LIMIT_ADMIN  = "admin"
+	RATE_LIMIT_AUTH   = "auth"
+)
+
+// RateLimiter limits the requests of every client with a token bucket per route group.
+// Clients are identified by their API key, or by their IP address when API keys are disabled.
+type RateLimiter struct {
+	Store  ratelimit.Store
+	Limits map[string]ratelimit.Limit // By route group, groups without a limit are not limited
+	Logger *slog.Logger
+	// Enabled is false to leave the routes unlimited
+	Enabled bool
+}
+
+func NewRateLimiter(store ratelimit.Store, limits map[string]ratelimit.Limit, logger *slog.Logger, enabled bool) *RateLimiter {
+	return &RateLimiter{
+		Store:   store,
+		Limits:  limits,
+		Logger:  logger,
+		Enabled: enabled,
+	}
+}
+
+// Limit takes a token from the client's bucket of the route group, refusing the request with 429
+// and Retry-After when it is empty. The RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// describe the bucket; with several buckets on a route, the one with the fewest remaining requests.
+// It must run after the API key authentication.
+func (limiter *RateLimiter) Limit(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := limiter.Limits[group]
+		if !limiter.Enabled || !ok {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		if apiKeyID := reqctx.APIKeyID(c.Request.Context()); apiKeyID != "" {
+			client = "key:" + apiKeyID
+		}
+		result, err := limiter.Store.Take(c.Request.Context(), group+":"+client, limit, time.Now())
+		if err != nil {
+			// An unavailable store must not take the API down with it
+			limiter.Logger.Warn("Failed to check rate limit, letting the request through", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		if remaining, err := strconv.Atoi(c.Writer.Header().Get("RateLimit-Remaining")); err != nil || result.Remaining <= remaining {
+			c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		}
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			newsResponse.Error(
+				c,
+				limiter.Logger,
+				http.StatusTooManyRequests,
+				"Rate limit exceeded, retry later",
+				fmt.Errorf("the %q rate limit of %d requests is exhausted", group, result.Limit),
+			)
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitFailedAuth takes a token from the RATE_LIMIT_AUTH bucket of the client's IP address for every request
+// refused with 401, so that API keys cannot be guessed: the other buckets are only taken from once a key is valid.
+// Once the bucket is empty, the requests of the IP address get 429 with Retry-After before their key is checked.
+// It must run before the API key authentication.
+func (limiter *RateLimiter) LimitFailedAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := limiter.Limits[RATE_LIMIT_AUTH]
+		if !limiter.Enabled || !ok {
+			c.Next()
+			return
+		}
+
+		key := RATE_LIMIT_AUTH + ":ip:" + c.ClientIP()
+		result, err := limiter.Store.Peek(c.Request.Context(), key, limit, time.Now())
+		if err != nil {
+			limiter.Logger.Warn("Failed to check rate limit, letting the request through", "group", RATE_LIMIT_AUTH, "error", err)
+			c.Next()
+			return
+		}
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			newsResponse.Error(
+				c,
+				limiter.Logger,
+				http.StatusTooManyRequests,
+				"Too many failed authentication attempts, retry later",
+				fmt.Errorf("the %q rate limit of %d requests is exhausted", RATE_LIMIT_AUTH, result.Limit),
+			)
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if _, err := limiter.Store.Take(c.Request.Context(), key, limit, time.Now()); err != nil {
+				limiter.Logger.Warn("Failed to count a failed authentication", "group", RATE_LIMIT_AUTH, "error", err)
+			}
+		}
+	}
+}
+
+// ceilSeconds rounds up to whole seconds, as the headers carry
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
+)
+
+// newFailedAuthRouter refuses the requests without the 'good' key with 401, behind LimitFailedAuth.
+func newFailedAuthRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.LimitFailedAuth())
+	router.GET("/news/latest", func(c *gin.Context) {
+		if c.GetHeader(API_KEY_HEADER) != "good" {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serveWithKey(router *gin.Engine, remoteAddr string, apiKey string) int {
+	req := httptest.NewRequest(http.MethodGet, "/news/latest", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(API_KEY_HEADER, apiKey)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestLimitFailedAuth(t *testing.T) {
+	limits := map[string]ratelimit.Limit{RATE_LIMIT_AUTH: {Rate: 1.0 / 60, Burst: 2}}
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(), limits, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	router := newFailedAuthRouter(limiter)
+
+	// Valid keys do not take from the bucket
+	for range 5 {
+		if code := serveWithKey(router, "192.0.2.1:1234", "good"); code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", code, http.StatusOK)
+		}
+	}
+	for range 2 {
+		if code := serveWithKey(router, "192.0.2.1:1234", "guess"); code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", code, http.StatusUnauthorized)
+		}
+	}
+	// The bucket is empty, so even a valid key is refused before it is checked
+	if code := serveWithKey(router, "192.0.2.1:1234", "good"); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := serveWithKey(router, "192.0.2.2:1234", "guess"); code != http.StatusUnauthorized {
+		t.Errorf("other IP status = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestLimitFailedAuthWithoutLimit(t *testing.T) {
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{}, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	router := newFailedAuthRouter(limiter)
+
+	for range 20 {
+		if code := serveWithKey(router, "192.0.2.1:1234", "guess"); code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsResponse"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+)
+
+// SummaryMode sets the summary mode of the listings from the 'summarize' query parameter,
+// none, eager (the default), lazy or cached_only, and refuses other values with 400.
+func SummaryMode(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode := c.Query("summarize")
+		if mode == "" {
+			c.Next()
+			return
+		}
+		if !newsArticle.SUMMARY_MODES[mode] {
+			newsResponse.Error(
+				c,
+				logger,
+				http.StatusBadRequest,
+				"Invalid summarize parameter",
+				fmt.Errorf("'summarize' must be one of none, eager, lazy or cached_only"),
+			)
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithSummaryMode(c.Request.Context(), mode))
+		c.Next()
+	}
+}
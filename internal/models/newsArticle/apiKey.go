@@ -0,0 +1,57 @@
+package newsArticle
+
+import "time"
+
+// API key scopes; SCOPE_ADMIN grants every scope.
+const (
+	SCOPE_READ         = "read"         // The news endpoints and reading the user endpoints
+	SCOPE_USERS_WRITE  = "users:write"  // Changing the preferences and bookmarks of users
+	SCOPE_EVENTS_WRITE = "events:write" // 'POST /events' and the endpoints recording user events, e.g. bookmarking
+	SCOPE_ADMIN        = "admin"        // Managing API keys
+)
+
+var API_KEY_SCOPES = map[string]bool{
+	SCOPE_READ:         true,
+	SCOPE_USERS_WRITE:  true,
+	SCOPE_EVENTS_WRITE: true,
+	SCOPE_ADMIN:        true,
+}
+
+// APIKey is a client's key, stored in the 'api_keys' collection. Only the SHA-256 hash of its secret is stored.
+// After a rotation the previous secret keeps working until PreviousExpiresAt.
+type APIKey struct {
+	ID                string     `bson:"_id" json:"id"`
+	Name              string     `bson:"name" json:"name"`
+	Scopes            []string   `bson:"scopes" json:"scopes"`
+	Hash              string     `bson:"hash" json:"-"`
+	PreviousHash      string     `bson:"previous_hash,omitempty" json:"-"`
+	PreviousExpiresAt *time.Time `bson:"previous_expires_at,omitempty" json:"previous_expires_at,omitempty"`
+	ExpiresAt         *time.Time `bson:"expires_at,omitempty" json:"expires_at"` // nil for keys which do not expire
+	RevokedAt         *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`
+	RotatedAt         *time.Time `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"`
+	Budget            LLMBudget  `bson:"budget" json:"budget"`
+}
+
+func (key APIKey) HasScope(scope string) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope || granted == SCOPE_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyInput is the body of 'POST /admin/api-keys'.
+type APIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional
+	Budget    LLMBudget  `json:"budget"`     // Optional
+}
+
+// IssuedAPIKey is a created or rotated key with its secret, which is only returned then.
+type IssuedAPIKey struct {
+	APIKey `bson:",inline"`
+	Key    string `bson:"-" json:"key"`
+}
@@ -0,0 +1,25 @@
+package newsArticle
+
+import "time"
+
+// ArticleNeighbors are the articles most often read in the same sessions as an article,
+// most similar first: "readers who read this also read".
+type ArticleNeighbors struct {
+	ArticleID string            `bson:"_id" json:"article_id"`
+	Neighbors []ArticleNeighbor `bson:"neighbors" json:"neighbors"`
+	UpdatedAt time.Time         `bson:"updated_at" json:"updated_at"`
+}
+
+// ArticleNeighbor has the cosine similarity of the two articles' sessions,
+// co_count / sqrt(sessions(article) * sessions(neighbor)), which keeps popular articles from neighboring everything.
+type ArticleNeighbor struct {
+	ArticleID    string  `bson:"article_id" json:"article_id"`
+	Similarity   float64 `bson:"similarity" json:"similarity"`
+	CoOccurrence int     `bson:"co_count" json:"co_count"`
+}
+
+// SessionArticles are the distinct articles read in one session.
+type SessionArticles struct {
+	SessionKey string   `bson:"_id"`
+	ArticleIDs []string `bson:"article_ids"`
+}
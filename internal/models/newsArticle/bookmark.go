@@ -0,0 +1,44 @@
+package newsArticle
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DEFAULT_BOOKMARK_COLLECTION holds the bookmarks saved without a collection name.
+const DEFAULT_BOOKMARK_COLLECTION = "saved"
+
+// Bookmark is an article saved by a user into one of their named collections, e.g. "read later".
+// An article can be in several collections of the same user, but only once in each.
+type Bookmark struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"bookmark_id"`
+	UserID     string             `bson:"user_id" json:"user_id"`
+	ArticleID  string             `bson:"article_id" json:"article_id"`
+	Collection string             `bson:"collection" json:"collection"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// BookmarkInput is the body of 'POST /users/<user_id>/bookmarks'.
+// The session and device are only recorded on the bookmark event.
+type BookmarkInput struct {
+	ArticleID  string `json:"article_id"`
+	Collection string `json:"collection"`
+	SessionID  string `json:"session_id"`
+	DeviceID   string `json:"device_id"`
+}
+
+// BookmarkedArticle is a bookmark with the current data of its article,
+// which is nil when the article was deleted since.
+type BookmarkedArticle struct {
+	Bookmark       `bson:",inline"`
+	Article        *NewsArticleDBResponse `bson:"article,omitempty" json:"article"`
+	ArticleDeleted bool                   `bson:"-" json:"article_deleted"`
+}
+
+// BookmarkCollection is one of a user's bookmark collections with its number of bookmarks.
+type BookmarkCollection struct {
+	Name      string    `bson:"_id" json:"name"`
+	Count     int       `bson:"count" json:"count"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"` // When the latest bookmark was added
+}
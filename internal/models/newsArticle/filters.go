@@ -0,0 +1,22 @@
+package newsArticle
+
+import "time"
+
+// ArticleFilters are the optional filters shared by the listing endpoints.
+// Empty fields are ignored.
+type ArticleFilters struct {
+	CountryCode   string    // ISO 3166-1 alpha-2, e.g. "IN"
+	Region        string    // State or province, e.g. "Jharkhand"
+	Category      string    // Matched case-insensitively anywhere in the category, like the '/category' endpoint
+	Source        string    // Matched case-insensitively anywhere in the source name, like the '/source' endpoint
+	MinScore      float64   // Minimum relevance_score
+	PublishedFrom time.Time // Inclusive lower bound on publication_date
+	PublishedTo   time.Time // Inclusive upper bound on publication_date
+
+	// Set from the preferences of the requesting user
+	MutedSources  []string // Source names excluded case-insensitively
+	MutedKeywords []string // Words excluded from the title and description
+	Following     *Follows // When set, only articles matching one of the follows (the "following" tab)
+
+	ExcludeArticleIDs []string // Set from the read history of the requesting user with 'exclude_seen'
+}
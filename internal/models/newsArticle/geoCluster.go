@@ -0,0 +1,15 @@
+package newsArticle
+
+// GeoCluster is one grid cell of the map clusters with its article count, centroid and best article.
+type GeoCluster struct {
+	Cell struct {
+		X int `bson:"x"`
+		Y int `bson:"y"`
+	} `bson:"_id" json:"-"`
+	CellID            string                `bson:"-" json:"cell_id"` // "<zoom>/<x>/<y>"
+	Count             int                   `bson:"count" json:"count"`
+	CentroidLatitude  float64               `bson:"centroid_latitude" json:"centroid_latitude"`
+	CentroidLongitude float64               `bson:"centroid_longitude" json:"centroid_longitude"`
+	Bounds            []float64             `bson:"-" json:"bounds"` // [minLon, minLat, maxLon, maxLat]
+	TopArticle        NewsArticleDBResponse `bson:"top_article" json:"top_article"`
+}
@@ -0,0 +1,14 @@
+package newsArticle
+
+// GeoPoint is a GeoJSON point, stored in 'location' fields for the 2dsphere indexes.
+type GeoPoint struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"` // [longitude, latitude]
+}
+
+func NewGeoPoint(latitude float64, longitude float64) *GeoPoint {
+	return &GeoPoint{
+		Type:        "Point",
+		Coordinates: []float64{longitude, latitude},
+	}
+}
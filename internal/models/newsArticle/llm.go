@@ -5,4 +5,23 @@ type LLMEntitiesAndIntentOutput struct {
 	Intent   string        `json:"intent,omitempty"`
 	Entities []string      `json:"entities,omitempty"`
 	Keywords []string      `json:"keywords,omitempty"` // Important searchable terms
-}
\ No newline at end of file
+}
+// Roles of the messages of an LLM chat
+const (
+	LLM_ROLE_SYSTEM    = "system"
+	LLM_ROLE_USER      = "user"
+	LLM_ROLE_ASSISTANT = "assistant"
+)
+
+// LLMMessage is one message of an LLM chat.
+type LLMMessage struct {
+	Role    string `json:"role"` // One of the LLM_ROLE_*
+	Content string `json:"content"`
+}
+
+// LLMChatResult is the reply to an LLM chat, with the tokens it took.
+type LLMChatResult struct {
+	Content          string `json:"content"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
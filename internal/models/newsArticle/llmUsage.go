@@ -0,0 +1,38 @@
+package newsArticle
+
+import "time"
+
+// What happens to the requests of a key over its LLM budget
+const (
+	LLM_BUDGET_SKIP_SUMMARIES = "skip_summaries" // Articles are returned without summaries, searches without query understanding
+	LLM_BUDGET_REJECT         = "reject"         // Requests are refused with 402
+)
+
+// LLMBudget caps what an API key may spend on LLM calls, in USD. A nil limit falls back to the configured default,
+// and 0 means no limit. Days and months are in UTC.
+type LLMBudget struct {
+	DailyUSD   *float64 `bson:"daily_usd,omitempty" json:"daily_usd"`
+	MonthlyUSD *float64 `bson:"monthly_usd,omitempty" json:"monthly_usd"`
+}
+
+// LLMUsage is the LLM usage of an API key with a model on a day, stored in the 'llm_usage' collection.
+// APIKeyID is "" for requests without a key, when API keys are disabled.
+type LLMUsage struct {
+	APIKeyID         string    `bson:"api_key_id" json:"api_key_id"`
+	Day              string    `bson:"day" json:"day"` // "2006-01-02", in UTC
+	Model            string    `bson:"model" json:"model"`
+	Calls            int64     `bson:"calls" json:"calls"`
+	PromptTokens     int64     `bson:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64     `bson:"completion_tokens" json:"completion_tokens"`
+	CostUSD          float64   `bson:"cost_usd" json:"cost_usd"`
+	UpdatedAt        time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// LLMSpend is what an API key spent on LLM calls today and this month, in USD.
+type LLMSpend struct {
+	DailyUSD   float64 `bson:"daily_usd" json:"daily_usd"`
+	MonthlyUSD float64 `bson:"monthly_usd" json:"monthly_usd"`
+}
+
+// LLM_USAGE_DAY_LAYOUT is the layout of LLMUsage.Day. Being zero padded, days sort and compare in date order.
+const LLM_USAGE_DAY_LAYOUT = "2006-01-02"
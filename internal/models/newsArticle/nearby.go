@@ -0,0 +1,28 @@
+package newsArticle
+
+import "time"
+
+const (
+	NEARBY_RANK_DISTANCE = "distance"
+	NEARBY_RANK_RECENCY  = "recency"
+	NEARBY_RANK_BLEND    = "blend"
+)
+
+func IsValidNearbyRank(rank string) bool {
+	return rank == NEARBY_RANK_DISTANCE || rank == NEARBY_RANK_RECENCY || rank == NEARBY_RANK_BLEND
+}
+
+// NearbyRanking decides the order of nearby results.
+// 'distance' is nearest first, 'recency' is newest first, and 'blend' sorts by
+//
+//	DistanceWeight * 0.5^(distance_km / DistanceHalfKm)
+//	+ RecencyWeight * 0.5^(age / RecencyHalfLife)
+//	+ RelevanceWeight * relevance_score
+type NearbyRanking struct {
+	Rank            string
+	DistanceWeight  float64
+	RecencyWeight   float64
+	RelevanceWeight float64
+	DistanceHalfKm  float64
+	RecencyHalfLife time.Duration
+}
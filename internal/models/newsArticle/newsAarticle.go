@@ -16,14 +16,31 @@ type NewsArticleDBResponse struct {
     Latitude        float64   `bson:"latitude" json:"latitude"`
     Longitude       float64   `bson:"longitude" json:"longitude"`
     Category        []string  `bson:"category" json:"category"`
+    City            string    `bson:"city,omitempty" json:"city,omitempty"`
+    Region          string    `bson:"region,omitempty" json:"region,omitempty"`
+    CountryCode     string    `bson:"country_code,omitempty" json:"country_code,omitempty"`
+    PlaceName       string    `bson:"place_name,omitempty" json:"place_name,omitempty"`
 	LLMSummary      string    `bson:"llm_summary" json:"llm_summary"`
+	SummaryStatus   string    `bson:"-" json:"summary_status,omitempty"`                  // One of the SUMMARY_STATUS_* of 'llm_summary'
+	DistanceKm      *float64  `bson:"distance_km,omitempty" json:"distance_km,omitempty"` // Only set by nearby queries
+	RankScore       *float64  `bson:"rank_score,omitempty" json:"rank_score,omitempty"`   // Only set by blended rankings
+	TrendScore      *float64  `bson:"trend_score,omitempty" json:"trend_score,omitempty"` // Only set by trending queries
+	TrendRank       int       `bson:"-" json:"trend_rank,omitempty"`                      // 1 for the top trending article
+	Similarity      *float64  `bson:"-" json:"similarity,omitempty"`                      // Only set by "also read" recommendations
 }
 
 type UserEvent struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	ArticleID string             `json:"article_id" bson:"article_id"`
-	EventType string             `json:"event_type" bson:"event_type"` // view, click, share
-	Latitude  float64            `json:"latitude" bson:"latitude"`
-	Longitude float64            `json:"longitude" bson:"longitude"`
-	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
-}
\ No newline at end of file
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ClientEventID   string             `json:"client_event_id,omitempty" bson:"client_event_id,omitempty"` // Unique, used to drop retried events
+	ArticleID       string             `json:"article_id" bson:"article_id"`
+	EventType       string             `json:"event_type" bson:"event_type"` // One of EVENT_TYPES
+	UserID          string             `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	SessionID       string             `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	DeviceID        string             `json:"device_id,omitempty" bson:"device_id,omitempty"`
+	DurationMs      int64              `json:"duration_ms,omitempty" bson:"duration_ms,omitempty"` // Only for dwell events
+	Latitude        float64            `json:"latitude" bson:"latitude"`
+	Longitude       float64            `json:"longitude" bson:"longitude"`
+	Location        *GeoPoint          `json:"-" bson:"location,omitempty"` // GeoJSON copy of latitude/longitude for the 2dsphere index
+	ClientTimestamp *time.Time         `json:"client_timestamp,omitempty" bson:"client_timestamp,omitempty"`
+	Timestamp       time.Time          `json:"timestamp" bson:"timestamp"` // When the server received the event, used for trending
+}
@@ -0,0 +1,13 @@
+package newsArticle
+
+import "time"
+
+// ReadHistory is the capped set of the articles a user read most recently, oldest first.
+type ReadHistory struct {
+	UserID     string    `bson:"_id" json:"user_id"`
+	ArticleIDs []string  `bson:"article_ids" json:"article_ids"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// READ_EVENT_TYPES are the events which mark an article as read.
+var READ_EVENT_TYPES = []string{EVENT_VIEW, EVENT_CLICK}
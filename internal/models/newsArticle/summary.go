@@ -0,0 +1,92 @@
+package newsArticle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Summary statuses of listed articles
+const (
+	SUMMARY_STATUS_OK      = "ok"      // Generated for this request
+	SUMMARY_STATUS_CACHED  = "cached"  // Stored from an earlier request
+	SUMMARY_STATUS_FAILED  = "failed"  // Generation failed, 'llm_summary' is empty
+	SUMMARY_STATUS_SKIPPED = "skipped" // Not generated, as the request makes no LLM calls (e.g. over its budget) or asked for stored summaries only
+	SUMMARY_STATUS_PENDING = "pending" // Being generated in the background (lazy mode or past the request's summary time budget), stored once done
+)
+
+// Summary modes of the listings, the 'summarize' parameter
+const (
+	SUMMARY_MODE_EAGER       = "eager"       // Generate the missing summaries before responding, the default
+	SUMMARY_MODE_LAZY        = "lazy"        // Respond with the stored summaries and generate the missing ones in the background
+	SUMMARY_MODE_CACHED_ONLY = "cached_only" // Respond with the stored summaries only
+	SUMMARY_MODE_NONE        = "none"        // No summaries
+)
+
+var SUMMARY_MODES = map[string]bool{
+	SUMMARY_MODE_EAGER:       true,
+	SUMMARY_MODE_LAZY:        true,
+	SUMMARY_MODE_CACHED_ONLY: true,
+	SUMMARY_MODE_NONE:        true,
+}
+
+// ArticleSummary is the stored LLM summary of an article, in the 'summaries' collection, one per article,
+// model and prompt version. It is stale once the article's ContentHash changes.
+type ArticleSummary struct {
+	ArticleID     string    `bson:"article_id" json:"article_id"`
+	ContentHash   string    `bson:"content_hash" json:"content_hash"`
+	Model         string    `bson:"model" json:"model"`
+	PromptVersion string    `bson:"prompt_version" json:"prompt_version"`
+	Summary       string    `bson:"summary" json:"summary"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ArticleSummaryResult is the summary of one article returned by 'POST /news/:id/summary'.
+type ArticleSummaryResult struct {
+	ArticleID     string `json:"article_id"`
+	Summary       string `json:"llm_summary"`
+	SummaryStatus string `json:"summary_status"`
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+// SummaryFailure is an article whose summary failed to be pre-generated, in 'summary_failures'.
+// It is retried after NextAttemptAt, or as soon as the article's ContentHash changes.
+type SummaryFailure struct {
+	ArticleID     string    `bson:"_id" json:"article_id"`
+	ContentHash   string    `bson:"content_hash" json:"content_hash"`
+	Attempts      int       `bson:"attempts" json:"attempts"`
+	LastError     string    `bson:"last_error" json:"last_error"`
+	NextAttemptAt time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SummaryCheckpoint is how far a summary pre-generation pass went through the articles, newest first,
+// in 'job_checkpoints'. An interrupted pass resumes after the article it names.
+type SummaryCheckpoint struct {
+	Job             string    `bson:"_id" json:"job"`
+	PublicationDate string    `bson:"publication_date" json:"publication_date"`
+	ArticleID       string    `bson:"article_id" json:"article_id"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SummaryContentHash hashes what a summary is generated from, the title and description of the article.
+func SummaryContentHash(title string, description string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + description))
+	return hex.EncodeToString(sum[:])
+}
+
+// SummaryStatusCounts counts the articles by summary status, nil when none has one.
+func SummaryStatusCounts(articles []NewsArticleDBResponse) map[string]int {
+	var counts map[string]int
+	for _, article := range articles {
+		if article.SummaryStatus == "" {
+			continue
+		}
+		if counts == nil {
+			counts = map[string]int{}
+		}
+		counts[article.SummaryStatus]++
+	}
+	return counts
+}
@@ -0,0 +1,42 @@
+package newsArticle
+
+import "time"
+
+// TRENDING_WINDOWS are the supported values of the trending 'window' parameter.
+var TRENDING_WINDOWS = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+const DEFAULT_TRENDING_WINDOW = "24h"
+
+// TrendingScoring configures how user events are turned into a trend score.
+// Every event in the window adds its type's weight, halved for every HalfLife of age:
+//
+//	trend_score = sum(EventWeights[event_type] * 0.5^((Now - timestamp) / HalfLife))
+type TrendingScoring struct {
+	Now          time.Time
+	Window       time.Duration
+	HalfLife     time.Duration
+	EventWeights map[string]float64
+}
+
+const (
+	TRENDING_SCOPE_EVENTS   = "events"   // where the users' events happened
+	TRENDING_SCOPE_ARTICLES = "articles" // where the articles are located
+	TRENDING_SCOPE_BOTH     = "both"     // both of the above
+)
+
+func IsValidTrendingScope(scope string) bool {
+	return scope == TRENDING_SCOPE_EVENTS || scope == TRENDING_SCOPE_ARTICLES || scope == TRENDING_SCOPE_BOTH
+}
+
+// TrendingLocation limits trending to a circle around a point.
+// A nil *TrendingLocation means global trending.
+type TrendingLocation struct {
+	Scope     string  `json:"scope"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radius_km"`
+}
@@ -0,0 +1,59 @@
+package newsArticle
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TrendingSnapshot is the precomputed trending list of one window, category and geo cell.
+// Category "" is every category and Cell "" is global.
+type TrendingSnapshot struct {
+	ID       primitive.ObjectID      `bson:"_id,omitempty" json:"-"`
+	Window   string                  `bson:"window" json:"window"`
+	Category string                  `bson:"category" json:"category"`
+	Cell     string                  `bson:"cell" json:"cell"`
+	AsOf     time.Time               `bson:"as_of" json:"as_of"`
+	Entries  []TrendingSnapshotEntry `bson:"entries" json:"entries"`
+}
+
+type TrendingSnapshotEntry struct {
+	ArticleID string  `bson:"article_id" json:"article_id"`
+	Score     float64 `bson:"score" json:"score"`
+}
+
+// TrendingCellScore is an article's trend score from the events of one geo cell.
+// X and Y are nil for events without a location.
+type TrendingCellScore struct {
+	Key struct {
+		ArticleID string `bson:"article_id"`
+		X         *int   `bson:"x"`
+		Y         *int   `bson:"y"`
+	} `bson:"_id"`
+	Score float64 `bson:"score"`
+}
+
+// TrendingSource tells where a trending response came from, for the response metadata.
+type TrendingSource struct {
+	Source     string     `json:"source"` // TRENDING_SOURCE_SNAPSHOT or TRENDING_SOURCE_LIVE
+	AsOf       *time.Time `json:"snapshot_as_of,omitempty"`
+	AgeSeconds *float64   `json:"snapshot_age_seconds,omitempty"`
+	Cells      []string   `json:"snapshot_cells,omitempty"` // The geo cells of a location
+}
+
+const (
+	TRENDING_SOURCE_SNAPSHOT = "snapshot"
+	TRENDING_SOURCE_LIVE     = "live"
+)
+
+// TrendingCellKey is the "x:y" key of a geo cell, where x and y count 'cellSizeDeg' cells from longitude -180 and latitude -90.
+func TrendingCellKey(x int, y int) string {
+	return fmt.Sprintf("%d:%d", x, y)
+}
+
+// TrendingCellOf returns the key of the geo cell containing the coordinate.
+func TrendingCellOf(latitude float64, longitude float64, cellSizeDeg float64) string {
+	return TrendingCellKey(int(math.Floor((longitude+180)/cellSizeDeg)), int(math.Floor((latitude+90)/cellSizeDeg)))
+}
@@ -0,0 +1,56 @@
+package newsArticle
+
+import "time"
+
+const (
+	EVENT_IMPRESSION = "impression"
+	EVENT_VIEW       = "view"
+	EVENT_CLICK      = "click"
+	EVENT_SHARE      = "share"
+	EVENT_DWELL      = "dwell"
+	EVENT_BOOKMARK   = "bookmark"
+)
+
+var EVENT_TYPES = map[string]bool{
+	EVENT_IMPRESSION: true,
+	EVENT_VIEW:       true,
+	EVENT_CLICK:      true,
+	EVENT_SHARE:      true,
+	EVENT_DWELL:      true,
+	EVENT_BOOKMARK:   true,
+}
+
+func IsValidEventType(eventType string) bool {
+	return EVENT_TYPES[eventType]
+}
+
+// Per-event outcomes of an event batch
+const (
+	EVENT_ACCEPTED  = "accepted"
+	EVENT_REJECTED  = "rejected"
+	EVENT_DUPLICATE = "duplicate"
+	EVENT_DROPPED   = "dropped" // The event queue was full
+)
+
+// EventInput is one event of a 'POST /events' batch as sent by the client.
+// Latitude and Longitude are optional but must be sent together.
+type EventInput struct {
+	ClientEventID   string     `json:"client_event_id"`
+	EventType       string     `json:"event_type"`
+	ArticleID       string     `json:"article_id"`
+	UserID          string     `json:"user_id"`
+	SessionID       string     `json:"session_id"`
+	DeviceID        string     `json:"device_id"`
+	ClientTimestamp *time.Time `json:"client_timestamp"`
+	DurationMs      int64      `json:"duration_ms"` // Required for dwell events
+	Latitude        *float64   `json:"lat"`
+	Longitude       *float64   `json:"lon"`
+}
+
+// EventResult is the outcome of the event at Index in the batch.
+type EventResult struct {
+	Index         int    `json:"index"`
+	ClientEventID string `json:"client_event_id"`
+	Status        string `json:"status"` // EVENT_ACCEPTED, EVENT_REJECTED, EVENT_DUPLICATE or EVENT_DROPPED
+	Reason        string `json:"reason,omitempty"`
+}
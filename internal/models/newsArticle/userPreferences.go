@@ -0,0 +1,50 @@
+package newsArticle
+
+import "time"
+
+// UserPreferences are what a user follows and mutes, stored in the 'users' collection.
+// Categories, sources and keywords are stored lowercased and match case-insensitively.
+type UserPreferences struct {
+	UserID        string `bson:"_id" json:"user_id"`
+	Follows       `bson:",inline"`
+	MutedSources  []string  `bson:"muted_sources" json:"muted_sources"`
+	MutedKeywords []string  `bson:"muted_keywords" json:"muted_keywords"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+type Follows struct {
+	Categories []string     `bson:"followed_categories" json:"followed_categories"`
+	Sources    []string     `bson:"followed_sources" json:"followed_sources"`
+	Places     []SavedPlace `bson:"followed_places" json:"followed_places"`
+}
+
+func (follows Follows) IsEmpty() bool {
+	return len(follows.Categories) == 0 && len(follows.Sources) == 0 && len(follows.Places) == 0
+}
+
+// SavedPlace is a followed area: the articles within RadiusKm of the coordinate.
+type SavedPlace struct {
+	Name      string  `bson:"name" json:"name"`
+	Latitude  float64 `bson:"latitude" json:"latitude"`
+	Longitude float64 `bson:"longitude" json:"longitude"`
+	RadiusKm  float64 `bson:"radius_km" json:"radius_km"`
+}
+
+// Kinds of follows and mutes in the preferences endpoints
+const (
+	PREFERENCE_CATEGORIES = "categories"
+	PREFERENCE_SOURCES    = "sources"
+	PREFERENCE_PLACES     = "places"
+	PREFERENCE_KEYWORDS   = "keywords"
+)
+
+// FOLLOW_FIELDS and MUTE_FIELDS map the kinds which are plain lists to their stored field.
+var FOLLOW_FIELDS = map[string]string{
+	PREFERENCE_CATEGORIES: "followed_categories",
+	PREFERENCE_SOURCES:    "followed_sources",
+}
+
+var MUTE_FIELDS = map[string]string{
+	PREFERENCE_SOURCES:  "muted_sources",
+	PREFERENCE_KEYWORDS: "muted_keywords",
+}
@@ -0,0 +1,37 @@
+package newsArticle
+
+import "time"
+
+// UserProfile is a user's interest profile built from their events.
+// Every affinity decays by half each profile half-life, so recent interests weigh the most.
+type UserProfile struct {
+	UserID     string     `bson:"_id" json:"user_id"`
+	Categories []Affinity `bson:"categories" json:"categories"` // Lowercased category names
+	Sources    []Affinity `bson:"sources" json:"sources"`
+	Keywords   []Affinity `bson:"keywords" json:"keywords"`
+	// Typical location, the weighted mean of the event locations
+	Latitude       *float64  `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude      *float64  `bson:"longitude,omitempty" json:"longitude,omitempty"`
+	LocationWeight float64   `bson:"location_weight" json:"-"`
+	EventCount     int       `bson:"event_count" json:"event_count"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Affinity is the decayed, weighted count of events on articles with the key.
+// The keys are stored as values rather than document field names since source names may contain dots.
+type Affinity struct {
+	Key    string  `bson:"key" json:"key"`
+	Weight float64 `bson:"weight" json:"weight"`
+}
+
+// FeedCandidates are the articles considered for a personalized feed, by where they were found.
+type FeedCandidates struct {
+	Latest     []NewsArticleDBResponse `bson:"latest"`
+	Categories []NewsArticleDBResponse `bson:"categories"`
+	Sources    []NewsArticleDBResponse `bson:"sources"`
+}
+
+const (
+	FEED_STRATEGY_PERSONALIZED = "personalized"
+	FEED_STRATEGY_TRENDING     = "trending" // Fallback for users without events
+)
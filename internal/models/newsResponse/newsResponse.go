@@ -5,7 +5,9 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
 )
 
 // APIResponse defines the standard JSON response structure.
@@ -13,6 +15,7 @@ type APIResponse struct {
     Status  string      `json:"status"`
     Message string      `json:"message"`
     Articles  interface{} `json:"articles,omitempty"`
+    Data     interface{} `json:"data,omitempty"`
     Metadata interface{} `json:"metadata,omitempty"`
     ErrorDetails   interface{} `json:"errorDetails,omitempty"`
 }
@@ -29,20 +32,87 @@ func Success(
     logger.Info("API Success",
         slog.String("message", message),
         slog.String("path", c.Request.URL.Path),
+        slog.String("api_key_id", reqctx.APIKeyID(c.Request.Context())),
     )
 
 	c.JSON(statusCode, APIResponse{
         Status: constants.SUCCESS,
         Message: message,
         Articles: articles,
-        Metadata: map[string]interface{}{
-            "count": length,
-            "query": c.Request.URL.Query(),
-            "path": c.Request.URL.Path,
-        },
+        Metadata: buildMetadata(c, articles, length, nil),
+    })
+}
+
+// SuccessWithMetadata sends a standardized successful response of articles
+// with extra endpoint specific metadata (e.g. the radius used) merged into the standard metadata.
+func SuccessWithMetadata(
+    c *gin.Context,
+    logger *slog.Logger,
+    statusCode int,
+    message string,
+    articles interface{},
+    length int,
+    extra map[string]interface{},
+) {
+    logger.Info("API Success",
+        slog.String("message", message),
+        slog.String("path", c.Request.URL.Path),
+        slog.String("api_key_id", reqctx.APIKeyID(c.Request.Context())),
+    )
+
+    c.JSON(statusCode, APIResponse{
+        Status: constants.SUCCESS,
+        Message: message,
+        Articles: articles,
+        Metadata: buildMetadata(c, articles, length, extra),
     })
 }
 
+// SuccessWithData sends a standardized successful response whose payload is not a list of articles,
+// e.g. map clusters or per-event results.
+func SuccessWithData(
+    c *gin.Context,
+    logger *slog.Logger,
+    statusCode int,
+    message string,
+    data interface{},
+    length int,
+    extra map[string]interface{},
+) {
+    logger.Info("API Success",
+        slog.String("message", message),
+        slog.String("path", c.Request.URL.Path),
+        slog.String("api_key_id", reqctx.APIKeyID(c.Request.Context())),
+    )
+
+    c.JSON(statusCode, APIResponse{
+        Status: constants.SUCCESS,
+        Message: message,
+        Data: data,
+        Metadata: buildMetadata(c, nil, length, extra),
+    })
+}
+
+// buildMetadata builds the standard metadata, with the counts of the articles' summary statuses
+// and whether some summaries failed or are pending when there are articles.
+func buildMetadata(c *gin.Context, articles interface{}, length int, extra map[string]interface{}) map[string]interface{} {
+    metadata := map[string]interface{}{
+        "count": length,
+        "query": c.Request.URL.Query(),
+        "path": c.Request.URL.Path,
+    }
+    if newsArticles, ok := articles.([]newsArticle.NewsArticleDBResponse); ok {
+        if counts := newsArticle.SummaryStatusCounts(newsArticles); counts != nil {
+            metadata["summaries"] = counts
+            metadata["summaries_partial"] = counts[newsArticle.SUMMARY_STATUS_FAILED] > 0 || counts[newsArticle.SUMMARY_STATUS_PENDING] > 0
+        }
+    }
+    for key, value := range extra {
+        metadata[key] = value
+    }
+    return metadata
+}
+
 func Error(c *gin.Context, logger *slog.Logger, statusCode int, message string, err error) {
     errorDetails := ""
     if err != nil {
@@ -54,6 +124,7 @@ func Error(c *gin.Context, logger *slog.Logger, statusCode int, message string,
         slog.String("message", message),
         slog.String("internal_error", errorDetails),
         slog.String("path", c.Request.URL.Path),
+        slog.String("api_key_id", reqctx.APIKeyID(c.Request.Context())),
     )
 
     // Return a generic error message to the client.
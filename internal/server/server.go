@@ -1,13 +1,27 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/shivam-cse/contextual-news-api/pkg/startup"
 	"github.com/shivam-cse/contextual-news-api/pkg/logger"
+	"github.com/shivam-cse/contextual-news-api/pkg/metrics"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
 	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/middleware"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	services "github.com/shivam-cse/contextual-news-api/internal/services"
 	v1Handlers "github.com/shivam-cse/contextual-news-api/internal/handlers/v1"
+	"github.com/shivam-cse/contextual-news-api/internal/workers"
 )
 
 func Run() {
@@ -53,38 +67,191 @@ func Run() {
 	}
 	logger.Info("Indexes on news collection created successfully")
 
+	err = startup.CreateIndexOnUserEventColl(database)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on user event collection created successfully")
+
+	err = startup.CreateIndexOnTrendingSnapshotColl(database, config.TrendingSnapshotRetention)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on trending snapshot collection created successfully")
+
+	err = startup.CreateIndexOnBookmarkColl(database)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on bookmark collection created successfully")
+
+	err = startup.CreateIndexOnRateLimitColl(database)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on rate limit collection created successfully")
+
+	err = startup.CreateIndexOnLLMUsageColl(database)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on LLM usage collection created successfully")
+
+	err = startup.CreateIndexOnSummaryColl(database)
+	if err != nil {
+		logger.Error("Failed to create indexes", "error", err)
+		panic(err)
+	}
+	logger.Info("Indexes on summary collection created successfully")
+
 	// Create the news database interface
 	newsDbInterface := dbInterface.NewNewsDbInterface(database, logger)
 
 	// Create the LLM service
-	llmService, err := services.NewLLMOpenRouterService(config.LLMToken, config.LLMEndpoint, config.LLMModel, logger)
+	llmService, err := services.NewLLMProvider(config, logger)
 	if err != nil {
 		logger.Error("Failed to create LLM service", "error", err)
 		panic(err)
 	}
-	logger.Info("LLM service created successfully", "model", config.LLMModel)
+	logger.Info("LLM service created successfully", "provider", config.LLMProvider, "model", config.LLMModel)
+
+	// Create the user event writer, which flushes queued events in the background
+	eventWriter := workers.NewEventWriter(newsDbInterface, logger, config.EventQueueSize, config.EventBatchSize, config.EventFlushInterval, config.EventQueueFullMode)
 
 	// Create the news service
-	newsService := services.NewNewsService(newsDbInterface, logger, llmService)
+	newsService := services.NewNewsService(newsDbInterface, logger, llmService, config, eventWriter)
+
+	// Written events update the user interest profiles of the personalized feed
+	eventWriter.OnWritten = newsService.EventsWritten
+	eventWriter.Start()
+
+	// Create the summary generator, which generates the summaries of lazy listings in the background
+	summaryGenerator := workers.NewSummaryGenerator(newsService.GenerateQueuedSummary, logger, config.SummaryQueueSize, config.SummaryWorkers)
+	newsService.SummaryQueue = summaryGenerator
+	summaryGenerator.Start()
+
+	// Create the API key service, which authenticates the requests
+	apiKeyService := services.NewAPIKeyService(newsDbInterface, logger, config.APIKeyCacheTTL, config.APIKeyRotationGrace)
+	apiKeyAuth := middleware.NewAPIKeyAuth(apiKeyService, logger, config.APIKeysEnabled)
+	if !config.APIKeysEnabled {
+		logger.Warn("API keys are disabled, every route is open")
+	}
+
+	// Create the bearer JWT authentication of end users
+	jwtVerifier, err := startup.NewJWTVerifier(config)
+	if err != nil {
+		logger.Error("Failed to create JWT verifier", "error", err)
+		panic(err)
+	}
+	jwtAuth := middleware.NewJWTAuth(jwtVerifier, logger, config.JWTRequired)
+	if jwtVerifier == nil {
+		logger.Warn("No JWT secret or JWKS is configured, bearer tokens are refused")
+	}
+
+	// Create the rate limiter, whose buckets are shared between instances with the mongo store
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if config.RateLimitStore == "mongo" {
+		rateLimitStore = dbInterface.NewRateLimitStore(newsDbInterface, constants.RATE_LIMITS)
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore, startup.RateLimits(config), logger, config.RateLimitEnabled)
+	if !config.RateLimitEnabled {
+		logger.Warn("Rate limiting is disabled")
+	}
+
+	// Usage of the LLM is charged to the calling API keys, whose budgets stop their LLM calls
+	llmUsageService := services.NewLLMUsageService(newsDbInterface, apiKeyService, logger, config)
+	llmService.Usage = llmUsageService
+	llmBudgetGuard := middleware.NewLLMBudgetGuard(llmUsageService, logger, config.LLMBudgetExceededMode)
 
-	// Create the news handler
+	// Create the handlers
 	v1NewsHandler := v1Handlers.NewNewsHandler(newsService, logger)
+	v1APIKeyHandler := v1Handlers.NewAPIKeyHandler(apiKeyService, llmUsageService, logger)
 
 	// Set up the router
 	router := gin.Default()
+	// Only the configured proxies may set the client IP through X-Forwarded-For, as it keys the rate limits of keyless clients
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Error("Invalid TRUSTED_PROXIES", "error", err)
+		panic(err)
+	}
+	// Limit the failed authentication attempts of every IP address, before any key is checked
+	router.Use(rateLimiter.LimitFailedAuth())
 
 	// Register the routes for v1
-	v1Handlers.RegisterRoutes(router, v1NewsHandler)
+	v1Handlers.RegisterRoutes(router, v1NewsHandler, v1APIKeyHandler, apiKeyAuth, jwtAuth, rateLimiter, llmBudgetGuard)
+
+	// Expose the metrics for Prometheus, which scrapes them with an admin key
+	router.GET("/metrics", apiKeyAuth.Require(newsArticle.SCOPE_ADMIN), gin.WrapH(metrics.Handler()))
 	
 	// Register the routes for v2
 	// v2Handlers.RegisterRoutes(router, v2NewsHandler)
 
-	err = router.Run(config.ServerAddress + ":" + config.ServerPort)
-	
-	if err != nil {
-		logger.Error("Error starting server", "error", err)
-		panic(err)
+	// Cancelled on SIGINT/SIGTERM to stop the workers and shut the server down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start the background workers
+	if config.TrendingSnapshotsEnabled && config.TrendingSnapshotInterval > 0 {
+		go workers.NewPeriodicWorker("trending-snapshots", logger, config.TrendingSnapshotInterval, func(ctx context.Context) error {
+			_, err := newsService.BuildTrendingSnapshots(ctx, time.Now())
+			return err
+		}).Run(ctx)
+	}
+	if config.AlsoReadEnabled && config.AlsoReadInterval > 0 {
+		go workers.NewPeriodicWorker("article-neighbors", logger, config.AlsoReadInterval, func(ctx context.Context) error {
+			_, err := newsService.BuildArticleNeighbors(ctx, time.Now())
+			return err
+		}).Run(ctx)
+	}
+
+	if config.SummaryPregenEnabled && config.SummaryPregenInterval > 0 {
+		go workers.NewPeriodicWorker("summary-pregen", logger, config.SummaryPregenInterval, func(ctx context.Context) error {
+			_, err := newsService.PregenerateSummaries(ctx, services.SummaryBackfillOptions{
+				Job:          services.SUMMARY_PREGEN_JOB,
+				Since:        time.Now().Add(-config.SummaryPregenWindow),
+				MaxGenerated: config.SummaryPregenMaxPerRun,
+				PerMinute:    config.SummaryPregenPerMinute,
+			})
+			return err
+		}).Run(ctx)
 	}
 
-	logger.Info("Server running on", "address", config.ServerAddress, "port", config.ServerPort)
-}
\ No newline at end of file
+	server := &http.Server{
+		Addr:    config.ServerAddress + ":" + config.ServerPort,
+		Handler: router,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server running on", "address", config.ServerAddress, "port", config.ServerPort)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Error starting server", "error", err)
+			panic(err)
+		}
+	case <-ctx.Done():
+		logger.Info("Shutting down server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down server gracefully", "error", err)
+		}
+		// No request can queue events anymore, write the queued ones before MongoDB is disconnected
+		if err := eventWriter.Close(shutdownCtx); err != nil {
+			logger.Error("Failed to flush queued user events", "error", err)
+		}
+		if err := summaryGenerator.Close(shutdownCtx); err != nil {
+			logger.Error("Failed to stop summary generator", "error", err)
+		}
+		logger.Info("Server stopped")
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+// ErrArticleNotFound is returned for a request about an article which does not exist.
+var ErrArticleNotFound = errors.New("article not found")
+
+// maxSessionArticles skips sessions which read more articles than a person would, e.g. crawlers,
+// which would otherwise add a pair for every two articles they fetched.
+const maxSessionArticles = 50
+
+// alsoReadSeedEvents is how many of a user's latest reads seed the "also read" part of their feed.
+const alsoReadSeedEvents = 20
+
+type articlePair struct {
+	first, second string // first < second
+}
+
+// BuildArticleNeighbors counts how often every two articles were read in the same session over the last
+// AlsoReadHistory and stores, for each article, its AlsoReadNeighbors most similar articles by cosine similarity.
+// Pairs read together in fewer than AlsoReadMinCooccurrence sessions are ignored. It returns the number of articles stored.
+func (service *NewsService) BuildArticleNeighbors(ctx context.Context, now time.Time) (int, error) {
+	service.Logger.Debug("'Service Layer': Building article neighbors...")
+	now = now.UTC().Truncate(time.Millisecond)
+
+	eventTypes := make([]string, 0, len(service.Config.UserProfileEventWeights))
+	for eventType := range service.Config.UserProfileEventWeights {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sessions, err := service.DbInterface.FindSessionArticles(ctx, constants.USER_EVENT, eventTypes, now.Add(-service.Config.AlsoReadHistory))
+	if err != nil {
+		service.Logger.Error("Failed to fetch session articles", "error", err)
+		return 0, err
+	}
+
+	sessionCounts := map[string]int{}
+	pairCounts := map[articlePair]int{}
+	for _, session := range sessions {
+		if len(session.ArticleIDs) > maxSessionArticles {
+			continue
+		}
+		for i, first := range session.ArticleIDs {
+			sessionCounts[first]++
+			for _, second := range session.ArticleIDs[i+1:] {
+				if second < first {
+					pairCounts[articlePair{second, first}]++
+				} else {
+					pairCounts[articlePair{first, second}]++
+				}
+			}
+		}
+	}
+
+	neighbors := map[string][]newsArticle.ArticleNeighbor{}
+	for pair, count := range pairCounts {
+		if count < service.Config.AlsoReadMinCooccurrence {
+			continue
+		}
+		similarity := float64(count) / math.Sqrt(float64(sessionCounts[pair.first]*sessionCounts[pair.second]))
+		neighbors[pair.first] = append(neighbors[pair.first], newsArticle.ArticleNeighbor{ArticleID: pair.second, Similarity: similarity, CoOccurrence: count})
+		neighbors[pair.second] = append(neighbors[pair.second], newsArticle.ArticleNeighbor{ArticleID: pair.first, Similarity: similarity, CoOccurrence: count})
+	}
+
+	documents := make([]newsArticle.ArticleNeighbors, 0, len(neighbors))
+	for articleID, articleNeighbors := range neighbors {
+		sort.Slice(articleNeighbors, func(i, j int) bool {
+			if articleNeighbors[i].Similarity != articleNeighbors[j].Similarity {
+				return articleNeighbors[i].Similarity > articleNeighbors[j].Similarity
+			}
+			return articleNeighbors[i].ArticleID < articleNeighbors[j].ArticleID
+		})
+		if size := service.Config.AlsoReadNeighbors; size > 0 && len(articleNeighbors) > size {
+			articleNeighbors = articleNeighbors[:size]
+		}
+		documents = append(documents, newsArticle.ArticleNeighbors{ArticleID: articleID, Neighbors: articleNeighbors, UpdatedAt: now})
+	}
+
+	if err := service.DbInterface.ReplaceArticleNeighbors(ctx, constants.ARTICLE_NEIGHBORS, documents, now); err != nil {
+		service.Logger.Error("Failed to store article neighbors", "error", err)
+		return 0, err
+	}
+
+	service.Logger.Info(fmt.Sprintf("Stored neighbors of %d articles from %d sessions", len(documents), len(sessions)))
+	return len(documents), nil
+}
+
+// AlsoReadService fetches the articles most read together with the article, most similar first,
+// each with its similarity. It returns ErrArticleNotFound when the article does not exist.
+func (service *NewsService) AlsoReadService(
+	ctx context.Context,
+	articleID string,
+	articleLimit int,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	service.Logger.Debug("'Service Layer': Fetching also read articles...")
+
+	exists, err := service.DbInterface.FindExistingArticleIDs(ctx, constants.NEWS, []string{articleID})
+	if err != nil {
+		service.Logger.Error("Failed to check article ID", "error", err)
+		return nil, err
+	}
+	if !exists[articleID] {
+		return nil, ErrArticleNotFound
+	}
+
+	neighbors, err := service.DbInterface.FindArticleNeighbors(ctx, constants.ARTICLE_NEIGHBORS, []string{articleID})
+	if err != nil {
+		service.Logger.Error("Failed to fetch article neighbors", "error", err)
+		return nil, err
+	}
+	similarities := map[string]float64{}
+	for _, neighbor := range neighbors[articleID].Neighbors {
+		similarities[neighbor.ArticleID] = neighbor.Similarity
+	}
+
+	articles, err := service.articlesByScore(ctx, similarities, articleLimit, filters)
+	if err != nil {
+		service.Logger.Error("Failed to fetch also read articles", "error", err)
+		return nil, err
+	}
+	for i := range articles {
+		similarity := similarities[articles[i].ID]
+		articles[i].Similarity = &similarity
+	}
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d also read articles from database and creating summaries...", len(articles)))
+	// Summarize the articles
+	articles = service.ArticleSummaryHelper(ctx, articles)
+	service.Logger.Info(fmt.Sprintf("Summarized %d also read articles", len(articles)))
+
+	return articles, nil
+}
+
+// alsoReadScores returns the neighbors of the user's latest reads, scored by their summed similarity
+// relative to the best one, without the articles the user read.
+func (service *NewsService) alsoReadScores(ctx context.Context, userID string) (map[string]float64, error) {
+	eventTypes := make([]string, 0, len(service.Config.UserProfileEventWeights))
+	for eventType := range service.Config.UserProfileEventWeights {
+		eventTypes = append(eventTypes, eventType)
+	}
+	events, err := service.DbInterface.FindUserEvents(ctx, constants.USER_EVENT, userID, eventTypes, time.Now().Add(-service.Config.AlsoReadHistory), alsoReadSeedEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	read := map[string]bool{}
+	seeds := []string{}
+	for _, event := range events {
+		if !read[event.ArticleID] {
+			read[event.ArticleID] = true
+			seeds = append(seeds, event.ArticleID)
+		}
+	}
+	neighbors, err := service.DbInterface.FindArticleNeighbors(ctx, constants.ARTICLE_NEIGHBORS, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := map[string]float64{}
+	best := 0.0
+	for _, articleNeighbors := range neighbors {
+		for _, neighbor := range articleNeighbors.Neighbors {
+			if read[neighbor.ArticleID] {
+				continue
+			}
+			scores[neighbor.ArticleID] += neighbor.Similarity
+			best = math.Max(best, scores[neighbor.ArticleID])
+		}
+	}
+	for articleID := range scores {
+		scores[articleID] /= best
+	}
+	return scores, nil
+}
+
+// articlesByScore fetches the scored articles which match the filters, highest score first, at most 'articleLimit' when it is positive.
+func (service *NewsService) articlesByScore(
+	ctx context.Context,
+	scores map[string]float64,
+	articleLimit int,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	articleIDs := make([]string, 0, len(scores))
+	for articleID := range scores {
+		articleIDs = append(articleIDs, articleID)
+	}
+	articles, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(articles, func(i, j int) bool {
+		if scores[articles[i].ID] != scores[articles[j].ID] {
+			return scores[articles[i].ID] > scores[articles[j].ID]
+		}
+		return articles[i].ID < articles[j].ID
+	})
+	if articleLimit > 0 && len(articles) > articleLimit {
+		articles = articles[:articleLimit]
+	}
+	return articles, nil
+}
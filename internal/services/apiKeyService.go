@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+var (
+	// ErrInvalidAPIKey is returned for a malformed, unknown or revoked key, or a wrong secret.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyExpired is returned for a key past its expiry.
+	ErrAPIKeyExpired = errors.New("API key expired")
+	// ErrAPIKeyNotFound is returned when managing a key which does not exist.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidAPIKeyInput is wrapped by the errors about an invalid new key.
+	ErrInvalidAPIKeyInput = errors.New("invalid API key input")
+)
+
+// apiKeyPrefix starts every key, which reads "cnk_<id>_<secret>", so that leaked keys are easy to recognize.
+const apiKeyPrefix = "cnk_"
+
+const (
+	maxCachedAPIKeys = 10000
+	// unknownAPIKeyCacheTTL bounds how long an unknown key ID is remembered, so that a key created
+	// on another instance works here soon
+	unknownAPIKeyCacheTTL = 5 * time.Second
+)
+
+// APIKeyService issues the API keys of clients and authenticates requests with them.
+// Validated keys are cached for 'cacheTTL', so a key revoked or rotated on another instance
+// stops working there after at most that long. Unknown key IDs are cached for at most unknownAPIKeyCacheTTL.
+type APIKeyService struct {
+	DbInterface *dbInterface.NewsDbInterface
+	Logger      *slog.Logger
+	// RotationGrace is how long the previous secret of a rotated key keeps working
+	RotationGrace time.Duration
+
+	cache       *utils.TTLCache[string, *newsArticle.APIKey]
+	unknownKeys *utils.TTLCache[string, struct{}]
+}
+
+func NewAPIKeyService(
+	dbInterface *dbInterface.NewsDbInterface,
+	logger *slog.Logger,
+	cacheTTL time.Duration,
+	rotationGrace time.Duration,
+) *APIKeyService {
+	return &APIKeyService{
+		DbInterface:   dbInterface,
+		Logger:        logger,
+		RotationGrace: rotationGrace,
+		cache:         utils.NewTTLCache[string, *newsArticle.APIKey](cacheTTL, maxCachedAPIKeys),
+		unknownKeys:   utils.NewTTLCache[string, struct{}](min(cacheTTL, unknownAPIKeyCacheTTL), maxCachedAPIKeys),
+	}
+}
+
+// Authenticate returns the key matching the raw key sent by a client. It returns ErrInvalidAPIKey
+// or ErrAPIKeyExpired when the key must be refused.
+func (service *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*newsArticle.APIKey, error) {
+	id, secret, ok := parseAPIKey(rawKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := service.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	hash := hashAPIKeySecret(secret)
+	matches := subtle.ConstantTimeCompare([]byte(hash), []byte(key.Hash)) == 1
+	if !matches && key.PreviousHash != "" && key.PreviousExpiresAt != nil && now.Before(*key.PreviousExpiresAt) {
+		matches = subtle.ConstantTimeCompare([]byte(hash), []byte(key.PreviousHash)) == 1
+	}
+	if !matches {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+	return key, nil
+}
+
+// GetAPIKey returns the key with the ID, revoked or not, or nil when there is none. Keys are cached.
+func (service *APIKeyService) GetAPIKey(ctx context.Context, id string) (*newsArticle.APIKey, error) {
+	if _, unknown := service.unknownKeys.Get(id); unknown {
+		return nil, nil
+	}
+	key, cached := service.cache.Get(id)
+	if cached {
+		return key, nil
+	}
+	key, err := service.DbInterface.FindAPIKey(ctx, constants.API_KEYS, id)
+	if err != nil {
+		service.Logger.Error("Failed to fetch API key", "api_key_id", id, "error", err)
+		return nil, err
+	}
+	// Unknown keys are cached briefly, so that guessing does not reach the database every time
+	if key == nil {
+		service.unknownKeys.Set(id, struct{}{})
+		return nil, nil
+	}
+	service.cache.Set(id, key)
+	return key, nil
+}
+
+// CreateAPIKey issues a new key. Its secret is only returned here.
+func (service *APIKeyService) CreateAPIKey(ctx context.Context, input newsArticle.APIKeyInput) (*newsArticle.IssuedAPIKey, error) {
+	service.Logger.Debug("'Service Layer': Creating API key...")
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a name is required", ErrInvalidAPIKeyInput)
+	}
+	if len(input.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyInput)
+	}
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range input.Scopes {
+		if !newsArticle.API_KEY_SCOPES[scope] {
+			return nil, fmt.Errorf("%w: scope %q must be one of read, users:write, events:write or admin", ErrInvalidAPIKeyInput, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	now := time.Now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyInput)
+	}
+	if err := validateLLMBudget(input.Budget); err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	key := newsArticle.APIKey{
+		ID:        id,
+		Name:      name,
+		Scopes:    scopes,
+		Hash:      hashAPIKeySecret(secret),
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: now.UTC().Truncate(time.Millisecond),
+		Budget:    input.Budget,
+	}
+	if err := service.DbInterface.InsertAPIKey(ctx, constants.API_KEYS, key); err != nil {
+		service.Logger.Error("Failed to store API key", "error", err)
+		return nil, err
+	}
+	service.cache.Delete(id)
+	service.unknownKeys.Delete(id)
+
+	service.Logger.Info("Created API key", "api_key_id", id, "scopes", scopes)
+	return &newsArticle.IssuedAPIKey{APIKey: key, Key: formatAPIKey(id, secret)}, nil
+}
+
+// RotateAPIKey gives the key a new secret, which is only returned here.
+// The previous secret keeps working for RotationGrace. It returns ErrAPIKeyNotFound for unknown or revoked keys.
+func (service *APIKeyService) RotateAPIKey(ctx context.Context, id string) (*newsArticle.IssuedAPIKey, error) {
+	service.Logger.Debug("'Service Layer': Rotating API key...")
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	key, err := service.DbInterface.RotateAPIKey(ctx, constants.API_KEYS, id, hashAPIKeySecret(secret), time.Now().Add(service.RotationGrace))
+	if err != nil {
+		service.Logger.Error("Failed to rotate API key", "api_key_id", id, "error", err)
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	service.cache.Delete(id)
+
+	service.Logger.Info("Rotated API key", "api_key_id", id)
+	return &newsArticle.IssuedAPIKey{APIKey: *key, Key: formatAPIKey(id, secret)}, nil
+}
+
+// RevokeAPIKey revokes the key at once. It returns ErrAPIKeyNotFound for unknown keys.
+func (service *APIKeyService) RevokeAPIKey(ctx context.Context, id string) (*newsArticle.APIKey, error) {
+	service.Logger.Debug("'Service Layer': Revoking API key...")
+
+	key, err := service.DbInterface.RevokeAPIKey(ctx, constants.API_KEYS, id)
+	if err != nil {
+		service.Logger.Error("Failed to revoke API key", "api_key_id", id, "error", err)
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	service.cache.Delete(id)
+
+	service.Logger.Info("Revoked API key", "api_key_id", id)
+	return key, nil
+}
+
+// SetAPIKeyBudget replaces the LLM budget of the key. It returns ErrAPIKeyNotFound for unknown keys.
+func (service *APIKeyService) SetAPIKeyBudget(ctx context.Context, id string, budget newsArticle.LLMBudget) (*newsArticle.APIKey, error) {
+	service.Logger.Debug("'Service Layer': Setting API key budget...")
+
+	if err := validateLLMBudget(budget); err != nil {
+		return nil, err
+	}
+	key, err := service.DbInterface.SetAPIKeyBudget(ctx, constants.API_KEYS, id, budget)
+	if err != nil {
+		service.Logger.Error("Failed to set API key budget", "api_key_id", id, "error", err)
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	service.cache.Delete(id)
+
+	service.Logger.Info("Set API key budget", "api_key_id", id)
+	return key, nil
+}
+
+func (service *APIKeyService) ListAPIKeys(ctx context.Context) ([]newsArticle.APIKey, error) {
+	service.Logger.Debug("'Service Layer': Fetching API keys...")
+
+	keys, err := service.DbInterface.FindAPIKeys(ctx, constants.API_KEYS)
+	if err != nil {
+		service.Logger.Error("Failed to fetch API keys", "error", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+func validateLLMBudget(budget newsArticle.LLMBudget) error {
+	if (budget.DailyUSD != nil && *budget.DailyUSD < 0) || (budget.MonthlyUSD != nil && *budget.MonthlyUSD < 0) {
+		return fmt.Errorf("%w: budgets must not be negative", ErrInvalidAPIKeyInput)
+	}
+	return nil
+}
+
+func formatAPIKey(id string, secret string) string {
+	return apiKeyPrefix + id + "_" + secret
+}
+
+func parseAPIKey(rawKey string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// hashAPIKeySecret hashes a secret with SHA-256. The secrets are random, so they need no salt or slow hash.
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+// ErrBookmarkNotFound is returned when removing a bookmark which does not exist.
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// ErrInvalidBookmark is wrapped by the errors about invalid bookmarks.
+var ErrInvalidBookmark = errors.New("invalid bookmark")
+
+// maxBookmarkCollectionLength bounds the name of a bookmark collection
+const maxBookmarkCollectionLength = 64
+
+// AddBookmarkService saves the article into the user's collection, DEFAULT_BOOKMARK_COLLECTION when none is given,
+// and records a bookmark event for trending and the user's profile. Saving an article already in the collection
+// returns the existing bookmark without a new event. It returns ErrArticleNotFound when the article does not exist.
+func (service *NewsService) AddBookmarkService(
+	ctx context.Context,
+	userID string,
+	input newsArticle.BookmarkInput,
+) (*newsArticle.Bookmark, bool, error) {
+	service.Logger.Debug("'Service Layer': Adding bookmark...")
+
+	collection, err := bookmarkCollectionName(input.Collection)
+	if err != nil {
+		return nil, false, err
+	}
+	if input.ArticleID == "" {
+		return nil, false, fmt.Errorf("%w: article_id is required", ErrInvalidBookmark)
+	}
+	if collection == "" {
+		collection = newsArticle.DEFAULT_BOOKMARK_COLLECTION
+	}
+
+	exists, err := service.DbInterface.FindExistingArticleIDs(ctx, constants.NEWS, []string{input.ArticleID})
+	if err != nil {
+		service.Logger.Error("Failed to check article ID", "error", err)
+		return nil, false, err
+	}
+	if !exists[input.ArticleID] {
+		return nil, false, ErrArticleNotFound
+	}
+
+	now := time.Now()
+	bookmark, created, err := service.DbInterface.InsertBookmark(ctx, constants.BOOKMARKS, newsArticle.Bookmark{
+		UserID:     userID,
+		ArticleID:  input.ArticleID,
+		Collection: collection,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		service.Logger.Error("Failed to store bookmark", "user_id", userID, "error", err)
+		return nil, false, err
+	}
+	if !created {
+		return bookmark, false, nil
+	}
+
+	// The bookmark is saved either way, a full event queue only loses its signal
+	event := newUserEvent(newsArticle.EventInput{
+		ClientEventID: "bookmark-" + bookmark.ID.Hex(),
+		EventType:     newsArticle.EVENT_BOOKMARK,
+		ArticleID:     bookmark.ArticleID,
+		UserID:        userID,
+		SessionID:     input.SessionID,
+		DeviceID:      input.DeviceID,
+	}, now)
+	if statuses, err := service.EventPublisher.Publish([]newsArticle.UserEvent{event}); err != nil || statuses[0] != newsArticle.EVENT_ACCEPTED {
+		service.Logger.Warn("Failed to queue bookmark event", "user_id", userID, "article_id", bookmark.ArticleID, "error", err)
+	}
+
+	service.Logger.Info(fmt.Sprintf("Bookmarked article %s into collection %q", bookmark.ArticleID, collection))
+	return bookmark, true, nil
+}
+
+// RemoveBookmarkService removes the article from the user's collection, or from all their collections
+// when 'collection' is empty. It returns ErrBookmarkNotFound when the article was not bookmarked.
+func (service *NewsService) RemoveBookmarkService(
+	ctx context.Context,
+	userID string,
+	articleID string,
+	collection string,
+) (int64, error) {
+	service.Logger.Debug("'Service Layer': Removing bookmark...")
+
+	collection, err := bookmarkCollectionName(collection)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := service.DbInterface.DeleteBookmarks(ctx, constants.BOOKMARKS, userID, articleID, collection)
+	if err != nil {
+		service.Logger.Error("Failed to remove bookmark", "user_id", userID, "error", err)
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, ErrBookmarkNotFound
+	}
+	return removed, nil
+}
+
+// ListBookmarksService fetches the user's latest bookmarks, in all collections when 'collection' is empty,
+// with the current data of their articles. Articles deleted since they were bookmarked are marked as such.
+func (service *NewsService) ListBookmarksService(
+	ctx context.Context,
+	userID string,
+	collection string,
+	articleLimit int,
+) ([]newsArticle.BookmarkedArticle, error) {
+	service.Logger.Debug("'Service Layer': Fetching bookmarks...")
+
+	collection, err := bookmarkCollectionName(collection)
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := service.DbInterface.FindBookmarkedArticles(ctx, constants.BOOKMARKS, constants.NEWS, userID, collection, int64(articleLimit))
+	if err != nil {
+		service.Logger.Error("Failed to fetch bookmarks", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d bookmarks of user %s", len(bookmarks), userID))
+	return bookmarks, nil
+}
+
+func (service *NewsService) BookmarkCollectionsService(ctx context.Context, userID string) ([]newsArticle.BookmarkCollection, error) {
+	service.Logger.Debug("'Service Layer': Fetching bookmark collections...")
+
+	collections, err := service.DbInterface.FindBookmarkCollections(ctx, constants.BOOKMARKS, userID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch bookmark collections", "user_id", userID, "error", err)
+		return nil, err
+	}
+	return collections, nil
+}
+
+// bookmarkCollectionName normalizes a collection name to trimmed lowercase, "" meaning none was given.
+func bookmarkCollectionName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) > maxBookmarkCollectionLength {
+		return "", fmt.Errorf("%w: collection names are at most %d characters", ErrInvalidBookmark, maxBookmarkCollectionLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+// ErrEventQueueFull is returned when the event queue has no room for a batch and is configured to refuse it.
+var ErrEventQueueFull = errors.New("event queue is full")
+
+// EventPublisher queues validated user events to be written asynchronously.
+// Publish returns the status of every event: accepted, duplicate when the same
+// client_event_id is still queued, or dropped when the queue had no room for it.
+type EventPublisher interface {
+	Publish(events []newsArticle.UserEvent) ([]string, error)
+}
+
+// MAX_EVENT_BATCH_SIZE is the largest number of events accepted in one 'POST /events' request.
+const MAX_EVENT_BATCH_SIZE = 500
+
+// maxClientClockSkew is how far in the future a client timestamp may be before the event is rejected.
+const maxClientClockSkew = 5 * time.Minute
+
+// IngestEventsService validates a batch of user events, queues the valid ones for writing and returns the outcome
+// of every event, in batch order. Invalid events and events for unknown articles are rejected, and events whose
+// client_event_id was already stored, is still queued or appears earlier in the batch are reported as duplicates.
+// When the request has a bearer token, 'userID' is its user, which every event gets; events sending their own
+// user_id are then rejected. It returns ErrEventQueueFull when the queue refuses the batch.
+func (service *NewsService) IngestEventsService(
+	ctx context.Context,
+	inputs []newsArticle.EventInput,
+	userID string,
+) ([]newsArticle.EventResult, error) {
+	service.Logger.Debug("'Service Layer': Ingesting user events...")
+	now := time.Now()
+
+	results := make([]newsArticle.EventResult, len(inputs))
+	clientEventIDs := []string{}
+	articleIDs := []string{}
+	for i, input := range inputs {
+		results[i] = newsArticle.EventResult{Index: i, ClientEventID: input.ClientEventID, Status: newsArticle.EVENT_ACCEPTED}
+		if reason := validateEventInput(input, now); reason != "" {
+			results[i].Status = newsArticle.EVENT_REJECTED
+			results[i].Reason = reason
+			continue
+		}
+		if userID != "" {
+			if input.UserID != "" {
+				results[i].Status = newsArticle.EVENT_REJECTED
+				results[i].Reason = "user_id is taken from the bearer token and must not be sent"
+				continue
+			}
+			inputs[i].UserID = userID
+		}
+		clientEventIDs = append(clientEventIDs, input.ClientEventID)
+		articleIDs = append(articleIDs, input.ArticleID)
+	}
+
+	existingArticles, err := service.DbInterface.FindExistingArticleIDs(ctx, constants.NEWS, articleIDs)
+	if err != nil {
+		service.Logger.Error("Failed to check article IDs of events", "error", err)
+		return nil, err
+	}
+	existingEvents, err := service.DbInterface.FindExistingClientEventIDs(ctx, constants.USER_EVENT, clientEventIDs)
+	if err != nil {
+		service.Logger.Error("Failed to check client event IDs", "error", err)
+		return nil, err
+	}
+
+	// batchIndexes[i] is the index in the batch of events[i]
+	events := []newsArticle.UserEvent{}
+	batchIndexes := []int{}
+	seen := map[string]bool{}
+	for i, input := range inputs {
+		if results[i].Status != newsArticle.EVENT_ACCEPTED {
+			continue
+		}
+		if existingEvents[input.ClientEventID] || seen[input.ClientEventID] {
+			results[i].Status = newsArticle.EVENT_DUPLICATE
+			continue
+		}
+		if !existingArticles[input.ArticleID] {
+			results[i].Status = newsArticle.EVENT_REJECTED
+			results[i].Reason = fmt.Sprintf("article %q does not exist", input.ArticleID)
+			continue
+		}
+		seen[input.ClientEventID] = true
+		events = append(events, newUserEvent(input, now))
+		batchIndexes = append(batchIndexes, i)
+	}
+
+	statuses, err := service.EventPublisher.Publish(events)
+	if err != nil {
+		if !errors.Is(err, ErrEventQueueFull) {
+			service.Logger.Error("Failed to queue user events", "error", err)
+		}
+		return nil, err
+	}
+	queued := 0
+	for i, status := range statuses {
+		results[batchIndexes[i]].Status = status
+		if status == newsArticle.EVENT_ACCEPTED {
+			queued++
+		}
+	}
+
+	service.Logger.Info(fmt.Sprintf("Queued %d of %d user events", queued, len(inputs)))
+	return results, nil
+}
+
+// EventsWritten updates the user profiles and read histories with newly written events.
+// It is the event writer's OnWritten hook.
+func (service *NewsService) EventsWritten(ctx context.Context, events []newsArticle.UserEvent) error {
+	return errors.Join(
+		service.UpdateUserProfiles(ctx, events),
+		service.UpdateReadHistories(ctx, events),
+	)
+}
+
+// validateEventInput returns why the event is invalid, or "" when it is valid.
+func validateEventInput(input newsArticle.EventInput, now time.Time) string {
+	switch {
+	case input.ClientEventID == "":
+		return "client_event_id is required"
+	case !newsArticle.IsValidEventType(input.EventType):
+		return fmt.Sprintf("event_type %q must be one of impression, view, click, share, dwell or bookmark", input.EventType)
+	case input.ArticleID == "":
+		return "article_id is required"
+	case input.EventType == newsArticle.EVENT_DWELL && input.DurationMs <= 0:
+		return "dwell events require a positive duration_ms"
+	case input.EventType != newsArticle.EVENT_DWELL && input.DurationMs != 0:
+		return "duration_ms is only allowed on dwell events"
+	case (input.Latitude == nil) != (input.Longitude == nil):
+		return "lat and lon must be sent together"
+	case input.Latitude != nil && (*input.Latitude < -90 || *input.Latitude > 90):
+		return "lat must be between -90 and 90"
+	case input.Longitude != nil && (*input.Longitude < -180 || *input.Longitude > 180):
+		return "lon must be between -180 and 180"
+	case input.ClientTimestamp != nil && input.ClientTimestamp.After(now.Add(maxClientClockSkew)):
+		return "client_timestamp is in the future"
+	}
+	return ""
+}
+
+// newUserEvent builds the stored event. Its timestamp is the time it was received,
+// so that trending is not skewed by client clocks; the client's time is kept as client_timestamp.
+func newUserEvent(input newsArticle.EventInput, now time.Time) newsArticle.UserEvent {
+	event := newsArticle.UserEvent{
+		ClientEventID:   input.ClientEventID,
+		ArticleID:       input.ArticleID,
+		EventType:       input.EventType,
+		UserID:          input.UserID,
+		SessionID:       input.SessionID,
+		DeviceID:        input.DeviceID,
+		DurationMs:      input.DurationMs,
+		ClientTimestamp: input.ClientTimestamp,
+		Timestamp:       now,
+	}
+	if input.Latitude != nil && input.Longitude != nil {
+		event.Latitude = *input.Latitude
+		event.Longitude = *input.Longitude
+		event.Location = newsArticle.NewGeoPoint(*input.Latitude, *input.Longitude)
+	}
+	return event
+}
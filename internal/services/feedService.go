@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+const (
+	// maxProfileEvents is how many of a user's latest events are read to build a new profile
+	maxProfileEvents = 1000
+	// maxProfileAffinities bounds the categories, sources and keywords kept on a profile
+	maxProfileAffinities = 50
+	// feedCandidatesPerGroup is how many candidates are fetched per group (latest, categories, sources, local, also read)
+	feedCandidatesPerGroup = 100
+)
+
+// Share of each signal in the affinity of an article to a profile
+const (
+	categoryAffinityShare = 0.4
+	sourceAffinityShare   = 0.2
+	keywordAffinityShare  = 0.2
+	locationAffinityShare = 0.2
+)
+
+// FeedNewsService builds the personalized feed of the user: candidate articles from the latest news,
+// the user's categories and sources and around their typical location, ranked by
+//
+//	FeedAffinityWeight * affinity + FeedFreshnessWeight * 0.5^(age / FeedFreshnessHalfLife) + FeedRelevanceWeight * relevance_score
+//
+// With 'blendAlsoRead', the articles read together with the user's latest reads are also candidates,
+// and FeedAlsoReadWeight times their relative similarity is added to the score.
+// Users without a profile get global trending instead. It returns the articles and the strategy used.
+func (service *NewsService) FeedNewsService(
+	ctx context.Context,
+	userID string,
+	articleLimit int,
+	blendAlsoRead bool,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, string, error) {
+	service.Logger.Debug("'Service Layer': Fetching personalized feed...")
+
+	profile, err := service.userProfile(ctx, userID)
+	if err != nil {
+		service.Logger.Error("Failed to load user profile", "user_id", userID, "error", err)
+		return nil, "", err
+	}
+	if profile == nil {
+		service.Logger.Info(fmt.Sprintf("No profile for user %s, falling back to trending", userID))
+		articles, _, err := service.TrendingNewsService(ctx, articleLimit, newsArticle.DEFAULT_TRENDING_WINDOW, nil, filters, nil, nil)
+		return articles, newsArticle.FEED_STRATEGY_TRENDING, err
+	}
+
+	alsoRead := map[string]float64{}
+	if blendAlsoRead {
+		alsoRead, err = service.alsoReadScores(ctx, userID)
+		if err != nil {
+			service.Logger.Error("Failed to fetch also read scores", "user_id", userID, "error", err)
+			return nil, "", err
+		}
+	}
+
+	candidates, err := service.feedCandidates(ctx, profile, alsoRead, filters)
+	if err != nil {
+		service.Logger.Error("Failed to fetch feed candidates", "error", err)
+		return nil, "", err
+	}
+
+	now := time.Now()
+	affinity := newProfileAffinity(profile, service.Config.FeedLocalRadiusKm)
+	for i := range candidates {
+		score := service.Config.FeedAffinityWeight*affinity.score(candidates[i]) +
+			service.Config.FeedFreshnessWeight*freshness(candidates[i], now, service.Config.FeedFreshnessHalfLife) +
+			service.Config.FeedRelevanceWeight*candidates[i].RelevanceScore +
+			service.Config.FeedAlsoReadWeight*alsoRead[candidates[i].ID]
+		candidates[i].RankScore = &score
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return *candidates[i].RankScore > *candidates[j].RankScore
+	})
+	if articleLimit > 0 && len(candidates) > articleLimit {
+		candidates = candidates[:articleLimit]
+	}
+
+	service.Logger.Info(fmt.Sprintf("Ranked %d feed articles for user %s and creating summaries...", len(candidates), userID))
+	// Summarize the articles
+	articles := service.ArticleSummaryHelper(ctx, candidates)
+	service.Logger.Info(fmt.Sprintf("Summarized %d feed articles", len(articles)))
+
+	return articles, newsArticle.FEED_STRATEGY_PERSONALIZED, nil
+}
+
+// feedCandidates fetches the de-duplicated candidate articles of the profile, including the scored also read articles.
+func (service *NewsService) feedCandidates(
+	ctx context.Context,
+	profile *newsArticle.UserProfile,
+	alsoRead map[string]float64,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	candidates, err := service.DbInterface.FindFeedCandidates(ctx, constants.NEWS, feedCandidatesPerGroup, affinityKeys(profile.Categories), affinityKeys(profile.Sources), filters)
+	if err != nil {
+		return nil, err
+	}
+	groups := [][]newsArticle.NewsArticleDBResponse{candidates.Latest, candidates.Categories, candidates.Sources}
+
+	if profile.Latitude != nil && profile.Longitude != nil {
+		local, err := service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, feedCandidatesPerGroup, *profile.Latitude, *profile.Longitude,
+			service.Config.FeedLocalRadiusKm, service.nearbyRanking(newsArticle.NEARBY_RANK_DISTANCE), filters)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, local)
+	}
+
+	if len(alsoRead) > 0 {
+		neighbors, err := service.articlesByScore(ctx, alsoRead, feedCandidatesPerGroup, filters)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, neighbors)
+	}
+
+	seen := map[string]bool{}
+	articles := []newsArticle.NewsArticleDBResponse{}
+	for _, group := range groups {
+		for _, article := range group {
+			if !seen[article.ID] {
+				seen[article.ID] = true
+				article.DistanceKm = nil
+				articles = append(articles, article)
+			}
+		}
+	}
+	return articles, nil
+}
+
+// profileAffinity scores how well an article matches a profile, between 0 and 1.
+// Each affinity is relative to the profile's strongest one of its kind.
+type profileAffinity struct {
+	categories, sources, keywords map[string]float64
+	latitude, longitude           *float64
+	localRadiusKm                 float64
+}
+
+func newProfileAffinity(profile *newsArticle.UserProfile, localRadiusKm float64) profileAffinity {
+	return profileAffinity{
+		categories:    normalizedAffinities(profile.Categories),
+		sources:       normalizedAffinities(profile.Sources),
+		keywords:      normalizedAffinities(profile.Keywords),
+		latitude:      profile.Latitude,
+		longitude:     profile.Longitude,
+		localRadiusKm: localRadiusKm,
+	}
+}
+
+func (affinity profileAffinity) score(article newsArticle.NewsArticleDBResponse) float64 {
+	category := 0.0
+	for _, name := range article.Category {
+		category = math.Max(category, affinity.categories[strings.ToLower(name)])
+	}
+
+	keyword := 0.0
+	for _, word := range utils.ExtractKeywords(article.Title) {
+		keyword += affinity.keywords[word]
+	}
+	// Three strong keywords are a full match
+	keyword = math.Min(keyword/3, 1)
+
+	score := categoryAffinityShare*category +
+		sourceAffinityShare*affinity.sources[strings.ToLower(article.SourceName)] +
+		keywordAffinityShare*keyword
+	if affinity.latitude == nil || affinity.longitude == nil || affinity.localRadiusKm <= 0 {
+		return score / (1 - locationAffinityShare)
+	}
+	// Halved at every local radius away from the typical location
+	distance := utils.HaversineKm(*affinity.latitude, *affinity.longitude, article.Latitude, article.Longitude)
+	return score + locationAffinityShare*math.Pow(0.5, distance/affinity.localRadiusKm)
+}
+
+// freshness halves for every half-life of the article's age, articles with an unknown date count as old.
+func freshness(article newsArticle.NewsArticleDBResponse, now time.Time, halfLife time.Duration) float64 {
+	date, ok := article.PublicationDate.(string)
+	if !ok || halfLife <= 0 {
+		return 0
+	}
+	published, err := time.Parse(constants.PUBLICATION_DATE_LAYOUT, date)
+	if err != nil {
+		return 0
+	}
+	age := math.Max(now.Sub(published).Hours(), 0)
+	return math.Pow(0.5, age/halfLife.Hours())
+}
+
+func normalizedAffinities(affinities []newsArticle.Affinity) map[string]float64 {
+	strongest := 0.0
+	for _, affinity := range affinities {
+		strongest = math.Max(strongest, affinity.Weight)
+	}
+	normalized := make(map[string]float64, len(affinities))
+	if strongest <= 0 {
+		return normalized
+	}
+	for _, affinity := range affinities {
+		normalized[affinity.Key] = affinity.Weight / strongest
+	}
+	return normalized
+}
+
+func affinityKeys(affinities []newsArticle.Affinity) []string {
+	keys := make([]string, len(affinities))
+	for i, affinity := range affinities {
+		keys[i] = affinity.Key
+	}
+	return keys
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+const (
+	// A 256px map tile is split into 4x4 cells, so a cluster is roughly 64px wide at any zoom level
+	CLUSTER_CELLS_PER_TILE = 4
+	MAX_CLUSTER_ZOOM       = 20
+	MAX_CLUSTERS           = 2000
+)
+
+// ClusterCellSizeDeg is the width of a cluster cell in degrees at a map zoom level.
+func ClusterCellSizeDeg(zoom int) float64 {
+	return 360 / math.Pow(2, float64(zoom)) / CLUSTER_CELLS_PER_TILE
+}
+
+// GeoClustersService returns the article clusters inside the box for a map zoom level.
+// It returns the clusters along with the cell size used.
+func (service *NewsService) GeoClustersService(
+	ctx context.Context,
+	bbox utils.BBox,
+	zoom int,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.GeoCluster, float64, error) {
+	service.Logger.Debug("'Service Layer': Fetching news article clusters...")
+
+	cellSizeDeg := ClusterCellSizeDeg(zoom)
+	clusters, err := service.DbInterface.FindArticleClusters(ctx, constants.NEWS, MAX_CLUSTERS, bbox, cellSizeDeg, filters)
+	if err != nil {
+		service.Logger.Error("Failed to fetch news article clusters", "error", err)
+		return nil, 0, err
+	}
+
+	for i := range clusters {
+		x, y := float64(clusters[i].Cell.X), float64(clusters[i].Cell.Y)
+		clusters[i].CellID = fmt.Sprintf("%d/%d/%d", zoom, clusters[i].Cell.X, clusters[i].Cell.Y)
+		clusters[i].Bounds = []float64{
+			x*cellSizeDeg - 180,
+			y*cellSizeDeg - 90,
+			(x+1)*cellSizeDeg - 180,
+			(y+1)*cellSizeDeg - 90,
+		}
+	}
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d news article clusters at zoom %d", len(clusters), zoom))
+	return clusters, cellSizeDeg, nil
+}
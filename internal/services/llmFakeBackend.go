@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+// FakeLLMResponses script the fake backend, e.g. in the LLM_FAKE_RESPONSES file:
+//
+//	{"rules": [{"match": "Query:", "responses": ["{\"intent\":\"search\"}"]}], "default": "{{truncateWords 20 .User}}"}
+//
+// The first rule whose 'match' is in one of the messages answers: with its responses in turn, the last
+// one repeating. Without a matching rule the default answers. Responses are text/template templates of
+// the chat (.System, .User, .Messages) with the functions words, lower, json, between and truncateWords.
+type FakeLLMResponses struct {
+	Rules   []FakeLLMRule `json:"rules"`
+	Default string        `json:"default"`
+}
+
+type FakeLLMRule struct {
+	Match     string   `json:"match"`
+	Responses []string `json:"responses"`
+}
+
+// DefaultFakeLLMResponses answer the summary and query prompts of the API from the prompts themselves:
+// the title and start of the description as the summary, and the query's words as search keywords.
+var DefaultFakeLLMResponses = FakeLLMResponses{
+	Rules: []FakeLLMRule{
+		{
+			Match:     strings.TrimSpace(constants.ARTICLE_NEWS_ENTITIES_AND_INTENT_SYSTEM_PROMPT),
+			Responses: []string{`{"intent":"search","entities":[],"keywords":{{json (words (between "Query: '''\"" "\"'''" .User))}}}`},
+		},
+		{
+			Match:     strings.TrimSpace(constants.ARTICLE_NEWS_SUMMARY_SYSTEM_PROMPT),
+			Responses: []string{`{{between "Title: '''" "'''" .User}}: {{truncateWords 50 (between "Description: '''" "'''" .User)}}`},
+		},
+	},
+	Default: `{{truncateWords 60 .User}}`,
+}
+
+// FakeBackend is a deterministic LLMBackend answering from scripted responses, to run without an LLM.
+// Tokens are counted as words.
+type FakeBackend struct {
+	rules    []fakeRule
+	fallback *template.Template
+
+	mu    sync.Mutex
+	calls map[int]int // Calls answered by each rule
+}
+
+type fakeRule struct {
+	match     string
+	responses []*template.Template
+}
+
+// fakeChat is what the fake responses are templates of.
+type fakeChat struct {
+	System   string // The system messages
+	User     string // The last user message
+	Messages []newsArticle.LLMMessage
+}
+
+// NewFakeBackend creates the fake backend from the JSON file of FakeLLMResponses at 'path',
+// or from DefaultFakeLLMResponses when it is empty.
+func NewFakeBackend(path string) (*FakeBackend, error) {
+	responses := DefaultFakeLLMResponses
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		responses = FakeLLMResponses{}
+		if err := json.Unmarshal(data, &responses); err != nil {
+			return nil, fmt.Errorf("invalid fake LLM responses %s: %w", path, err)
+		}
+	}
+	return NewFakeBackendFromResponses(responses)
+}
+
+func NewFakeBackendFromResponses(responses FakeLLMResponses) (*FakeBackend, error) {
+	backend := &FakeBackend{calls: map[int]int{}}
+	for i, rule := range responses.Rules {
+		if len(rule.Responses) == 0 {
+			return nil, fmt.Errorf("fake LLM rule %d has no responses", i)
+		}
+		parsed := fakeRule{match: rule.Match}
+		for j, response := range rule.Responses {
+			tmpl, err := parseFakeResponse(fmt.Sprintf("rule %d response %d", i, j), response)
+			if err != nil {
+				return nil, err
+			}
+			parsed.responses = append(parsed.responses, tmpl)
+		}
+		backend.rules = append(backend.rules, parsed)
+	}
+
+	fallback, err := parseFakeResponse("default", responses.Default)
+	if err != nil {
+		return nil, err
+	}
+	backend.fallback = fallback
+	return backend, nil
+}
+
+func (backend *FakeBackend) Chat(
+	ctx context.Context,
+	model string,
+	messages []newsArticle.LLMMessage,
+) (newsArticle.LLMChatResult, error) {
+	if err := ctx.Err(); err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+
+	chat := fakeChat{Messages: messages}
+	var systemMessages []string
+	promptTokens := 0
+	for _, message := range messages {
+		switch message.Role {
+		case newsArticle.LLM_ROLE_SYSTEM:
+			systemMessages = append(systemMessages, message.Content)
+		case newsArticle.LLM_ROLE_USER:
+			chat.User = message.Content
+		}
+		promptTokens += len(strings.Fields(message.Content))
+	}
+	chat.System = strings.Join(systemMessages, "\n")
+
+	var content bytes.Buffer
+	if err := backend.response(messages).Execute(&content, chat); err != nil {
+		return newsArticle.LLMChatResult{}, fmt.Errorf("fake LLM response: %w", err)
+	}
+
+	return newsArticle.LLMChatResult{
+		Content:          content.String(),
+		PromptTokens:     promptTokens,
+		CompletionTokens: len(strings.Fields(content.String())),
+	}, nil
+}
+
+// response is the next response of the first rule matching the messages, or the default one.
+func (backend *FakeBackend) response(messages []newsArticle.LLMMessage) *template.Template {
+	for i, rule := range backend.rules {
+		for _, message := range messages {
+			if !strings.Contains(message.Content, rule.match) {
+				continue
+			}
+			backend.mu.Lock()
+			call := backend.calls[i]
+			backend.calls[i]++
+			backend.mu.Unlock()
+			return rule.responses[min(call, len(rule.responses)-1)]
+		}
+	}
+	return backend.fallback
+}
+
+var fakeResponseFuncs = template.FuncMap{
+	"words": func(text string) []string {
+		return strings.Fields(strings.ToLower(text))
+	},
+	"lower": strings.ToLower,
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	// between returns the text between the first 'start' and the next 'end', "" when there is none
+	"between": func(start string, end string, text string) string {
+		_, after, found := strings.Cut(text, start)
+		if !found {
+			return ""
+		}
+		before, _, _ := strings.Cut(after, end)
+		return strings.TrimSpace(before)
+	},
+	"truncateWords": func(count int, text string) string {
+		words := strings.Fields(text)
+		if len(words) > count {
+			words = words[:count]
+		}
+		return strings.Join(words, " ")
+	},
+}
+
+func parseFakeResponse(name string, response string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(fakeResponseFuncs).Parse(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fake LLM %s: %w", name, err)
+	}
+	return tmpl, nil
+}
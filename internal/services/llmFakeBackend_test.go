@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newFakeLLMService is the LLM service over a fake backend answering with 'responses'.
+func newFakeLLMService(t *testing.T, responses FakeLLMResponses) *LLMService {
+	t.Helper()
+	backend, err := NewFakeBackendFromResponses(responses)
+	if err != nil {
+		t.Fatalf("NewFakeBackendFromResponses: %v", err)
+	}
+	return NewLLMService(backend, "fake-model", 0, discardLogger())
+}
+
+func userMessage(content string) []newsArticle.LLMMessage {
+	return []newsArticle.LLMMessage{{Role: newsArticle.LLM_ROLE_USER, Content: content}}
+}
+
+func TestFakeBackendScriptedResponses(t *testing.T) {
+	llm := newFakeLLMService(t, FakeLLMResponses{
+		Rules: []FakeLLMRule{
+			{Match: "weather", Responses: []string{"sunny", "rainy"}},
+			{Match: "sports", Responses: []string{"goal"}},
+		},
+		Default: "default answer",
+	})
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"the weather today", "sunny"},
+		{"the weather tomorrow", "rainy"},
+		// The last response of a rule repeats
+		{"the weather after", "rainy"},
+		{"sports news", "goal"},
+		{"sports news again", "goal"},
+		{"anything else", "default answer"},
+	}
+	for _, test := range tests {
+		result, err := llm.Chat(context.Background(), userMessage(test.message))
+		if err != nil {
+			t.Fatalf("Chat(%q): %v", test.message, err)
+		}
+		if result.Content != test.want {
+			t.Errorf("Chat(%q) = %q, want %q", test.message, result.Content, test.want)
+		}
+	}
+}
+
+func TestFakeBackendTemplatedResponses(t *testing.T) {
+	llm := newFakeLLMService(t, FakeLLMResponses{
+		Rules: []FakeLLMRule{
+			{Match: "Name:", Responses: []string{`Hello {{between "Name: '" "'" .User}} from {{lower .System}}`}},
+			{Match: "Words:", Responses: []string{`{{json (words .User)}}`}},
+		},
+		Default: `{{truncateWords 3 .User}}`,
+	})
+
+	tests := []struct {
+		messages []newsArticle.LLMMessage
+		want     string
+	}{
+		{
+			[]newsArticle.LLMMessage{
+				{Role: newsArticle.LLM_ROLE_SYSTEM, Content: "The SYSTEM"},
+				{Role: newsArticle.LLM_ROLE_USER, Content: "Name: 'Ada'"},
+			},
+			"Hello Ada from the system",
+		},
+		{userMessage("Words: Two WORDS"), `["words:","two","words"]`},
+		{userMessage("one two three four five"), "one two three"},
+	}
+	for _, test := range tests {
+		result, err := llm.Chat(context.Background(), test.messages)
+		if err != nil {
+			t.Fatalf("Chat: %v", err)
+		}
+		if result.Content != test.want {
+			t.Errorf("Chat = %q, want %q", result.Content, test.want)
+		}
+	}
+}
+
+func TestFakeBackendCountsTokensAsWords(t *testing.T) {
+	llm := newFakeLLMService(t, FakeLLMResponses{Default: "three word answer"})
+
+	result, err := llm.Chat(context.Background(), []newsArticle.LLMMessage{
+		{Role: newsArticle.LLM_ROLE_SYSTEM, Content: "be brief"},
+		{Role: newsArticle.LLM_ROLE_USER, Content: "what is new"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PromptTokens != 5 || result.CompletionTokens != 3 {
+		t.Errorf("tokens = %d prompt, %d completion, want 5 and 3", result.PromptTokens, result.CompletionTokens)
+	}
+}
+
+func TestFakeBackendEmptyResponse(t *testing.T) {
+	llm := newFakeLLMService(t, FakeLLMResponses{Default: "  "})
+
+	if _, err := llm.Chat(context.Background(), userMessage("anything")); !errors.Is(err, ErrLLMEmptyResponse) {
+		t.Errorf("Chat error = %v, want ErrLLMEmptyResponse", err)
+	}
+}
+
+func TestFakeBackendFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "responses.json")
+	data := `{"rules": [{"match": "ping", "responses": ["pong"]}], "default": "?"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	backend, err := NewFakeBackend(path)
+	if err != nil {
+		t.Fatalf("NewFakeBackend: %v", err)
+	}
+
+	result, err := backend.Chat(context.Background(), "fake-model", userMessage("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "pong" {
+		t.Errorf("Chat = %q, want pong", result.Content)
+	}
+}
+
+func TestFakeBackendInvalidResponses(t *testing.T) {
+	tests := map[string]FakeLLMResponses{
+		"rule without responses": {Rules: []FakeLLMRule{{Match: "x"}}},
+		"invalid template":       {Default: "{{.Unclosed"},
+	}
+	for name, responses := range tests {
+		if _, err := NewFakeBackendFromResponses(responses); err == nil {
+			t.Errorf("%s: NewFakeBackendFromResponses succeeded", name)
+		}
+	}
+}
+
+func TestExtractEntitiesAndIntent(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     newsArticle.LLMEntitiesAndIntentOutput
+		wantErr  bool
+	}{
+		{
+			name:     "plain JSON",
+			response: `{"intent":"nearby","entities":["Elon Musk"],"keywords":["tesla"]}`,
+			want:     newsArticle.LLMEntitiesAndIntentOutput{Intent: "nearby", Entities: []string{"Elon Musk"}, Keywords: []string{"tesla"}},
+		},
+		{
+			name:     "JSON in a markdown code block",
+			response: "Here you go:\n```json\n{\"intent\":\"category\",\"keywords\":[\"sports\"]}\n```",
+			want:     newsArticle.LLMEntitiesAndIntentOutput{Intent: "category", Keywords: []string{"sports"}},
+		},
+		{
+			name:     "not JSON",
+			response: "I cannot help with that",
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			llm := newFakeLLMService(t, FakeLLMResponses{Default: test.response})
+			output, err := llm.ExtractEntitiesAndIntent(context.Background(), "system", "query")
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("ExtractEntitiesAndIntent succeeded with %+v", output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractEntitiesAndIntent: %v", err)
+			}
+			if !reflect.DeepEqual(output, test.want) {
+				t.Errorf("ExtractEntitiesAndIntent = %+v, want %+v", output, test.want)
+			}
+		})
+	}
+}
+
+// The default responses answer the API's own prompts, so the API runs without an LLM.
+func TestDefaultFakeLLMResponses(t *testing.T) {
+	llm := newFakeLLMService(t, DefaultFakeLLMResponses)
+
+	output, err := llm.ExtractEntitiesAndIntent(
+		context.Background(),
+		constants.ARTICLE_NEWS_ENTITIES_AND_INTENT_SYSTEM_PROMPT,
+		fmt.Sprintf(constants.ARTICLE_NEWS_ENTITIES_AND_INTENT_USER_PROMPT, "Tesla Earnings"),
+	)
+	if err != nil {
+		t.Fatalf("ExtractEntitiesAndIntent: %v", err)
+	}
+	want := newsArticle.LLMEntitiesAndIntentOutput{Intent: "search", Entities: []string{}, Keywords: []string{"tesla", "earnings"}}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("ExtractEntitiesAndIntent = %+v, want %+v", output, want)
+	}
+
+	summary, err := llm.GenerateSummary(
+		context.Background(),
+		constants.ARTICLE_NEWS_SUMMARY_SYSTEM_PROMPT,
+		fmt.Sprintf(constants.ARTICLE_NEWS_SUMMARY_USER_PROMPT, "Rain in Paris", "Heavy rain fell over Paris on Monday."),
+	)
+	if err != nil {
+		t.Fatalf("GenerateSummary: %v", err)
+	}
+	if !strings.HasPrefix(summary, "Rain in Paris: Heavy rain fell") {
+		t.Errorf("GenerateSummary = %q, want the title and description", summary)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+)
+
+// DEFAULT_OLLAMA_ENDPOINT is the base URL of the Ollama backend when LLM_ENDPOINT is not set.
+const DEFAULT_OLLAMA_ENDPOINT = "http://localhost:11434"
+
+// OllamaBackend is the LLMBackend of an Ollama server, through its '/api/chat'.
+type OllamaBackend struct {
+	HTTPClient *http.Client
+	Endpoint   string
+}
+
+func NewOllamaBackend(endpoint string) *OllamaBackend {
+	if endpoint == "" {
+		endpoint = DEFAULT_OLLAMA_ENDPOINT
+	}
+	return &OllamaBackend{
+		HTTPClient: http.DefaultClient,
+		Endpoint:   strings.TrimRight(endpoint, "/"),
+	}
+}
+
+type ollamaChatRequest struct {
+	Model    string                   `json:"model"`
+	Messages []newsArticle.LLMMessage `json:"messages"`
+	Stream   bool                     `json:"stream"`
+}
+
+type ollamaChatResponse struct {
+	Message         newsArticle.LLMMessage `json:"message"`
+	PromptEvalCount int                    `json:"prompt_eval_count"`
+	EvalCount       int                    `json:"eval_count"`
+}
+
+func (backend *OllamaBackend) Chat(
+	ctx context.Context,
+	model string,
+	messages []newsArticle.LLMMessage,
+) (newsArticle.LLMChatResult, error) {
+	var response ollamaChatResponse
+	err := postJSON(ctx, backend.HTTPClient, backend.Endpoint+"/api/chat", "", ollamaChatRequest{
+		Model:    model,
+		Messages: messages,
+		Stream:   false,
+	}, &response)
+	if err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+
+	return newsArticle.LLMChatResult{
+		Content:          response.Message.Content,
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+	}, nil
+}
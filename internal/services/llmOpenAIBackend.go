@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+)
+
+// DEFAULT_OPENAI_ENDPOINT is the base URL of the OpenAI compatible backend when LLM_ENDPOINT is not set.
+const DEFAULT_OPENAI_ENDPOINT = "https://api.openai.com/v1"
+
+// OpenAICompatibleBackend is the LLMBackend of any API serving OpenAI's '/chat/completions',
+// e.g. OpenAI itself, vLLM or a self-hosted gateway.
+type OpenAICompatibleBackend struct {
+	HTTPClient *http.Client
+	Endpoint   string // Base URL, which '/chat/completions' is appended to
+	Token      string // Sent as a bearer token when set
+}
+
+func NewOpenAICompatibleBackend(token string, endpoint string) *OpenAICompatibleBackend {
+	if endpoint == "" {
+		endpoint = DEFAULT_OPENAI_ENDPOINT
+	}
+	return &OpenAICompatibleBackend{
+		HTTPClient: http.DefaultClient,
+		Endpoint:   strings.TrimRight(endpoint, "/"),
+		Token:      token,
+	}
+}
+
+type openAIChatRequest struct {
+	Model    string                   `json:"model"`
+	Messages []newsArticle.LLMMessage `json:"messages"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message newsArticle.LLMMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (backend *OpenAICompatibleBackend) Chat(
+	ctx context.Context,
+	model string,
+	messages []newsArticle.LLMMessage,
+) (newsArticle.LLMChatResult, error) {
+	var response openAIChatResponse
+	err := postJSON(ctx, backend.HTTPClient, backend.Endpoint+"/chat/completions", backend.Token, openAIChatRequest{
+		Model:    model,
+		Messages: messages,
+	}, &response)
+	if err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+	if len(response.Choices) == 0 {
+		return newsArticle.LLMChatResult{}, ErrLLMEmptyResponse
+	}
+
+	return newsArticle.LLMChatResult{
+		Content:          response.Choices[0].Message.Content,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+	}, nil
+}
+
+// postJSON posts the request as JSON, with the token as a bearer token when set, and decodes the JSON response.
+// A response other than 2xx is an error quoting the start of its body.
+func postJSON(ctx context.Context, client *http.Client, url string, token string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 512))
+		return fmt.Errorf("LLM request to %s failed with status %d: %s", url, httpResponse.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
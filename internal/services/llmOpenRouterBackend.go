@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+
+	"github.com/eduardolat/openroutergo"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+)
+
+// OpenRouterBackend is the LLMBackend of OpenRouter.
+type OpenRouterBackend struct {
+	client *openroutergo.Client
+}
+
+func NewOpenRouterBackend(token string, endpoint string) (*OpenRouterBackend, error) {
+	client, err := openroutergo.NewClient().
+		WithAPIKey(token).
+		WithBaseURL(endpoint).
+		Create()
+	if err != nil {
+		return nil, err
+	}
+	return &OpenRouterBackend{client: client}, nil
+}
+
+func (backend *OpenRouterBackend) Chat(
+	ctx context.Context,
+	model string,
+	messages []newsArticle.LLMMessage,
+) (newsArticle.LLMChatResult, error) {
+	completion := backend.client.
+		NewChatCompletion().
+		WithContext(ctx).
+		WithModel(model)
+	for _, message := range messages {
+		switch message.Role {
+		case newsArticle.LLM_ROLE_SYSTEM:
+			completion.WithSystemMessage(message.Content)
+		case newsArticle.LLM_ROLE_ASSISTANT:
+			completion.WithAssistantMessage(message.Content)
+		default:
+			completion.WithUserMessage(message.Content)
+		}
+	}
+
+	_, resp, err := completion.Execute()
+	if err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return newsArticle.LLMChatResult{}, ErrLLMEmptyResponse
+	}
+
+	return newsArticle.LLMChatResult{
+		Content:          resp.Choices[0].Message.Content,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}, nil
+}
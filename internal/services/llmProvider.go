@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+)
+
+// Backends of the LLM, the LLM_PROVIDER setting
+const (
+	LLM_PROVIDER_OPENROUTER = "openrouter"
+	LLM_PROVIDER_OPENAI     = "openai"
+	LLM_PROVIDER_OLLAMA     = "ollama"
+	LLM_PROVIDER_FAKE       = "fake"
+)
+
+// ErrLLMEmptyResponse is returned when the LLM replies without any content.
+var ErrLLMEmptyResponse = errors.New("LLM returned an empty response")
+
+// LLMProvider is the LLM the services use, for summaries, query understanding and plain chats.
+type LLMProvider interface {
+	// Model names the model, which stored summaries and LLM usage are kept by
+	Model() string
+	Chat(ctx context.Context, messages []newsArticle.LLMMessage) (newsArticle.LLMChatResult, error)
+	GenerateSummary(ctx context.Context, systemMessage string, userMessage string) (string, error)
+	ExtractEntitiesAndIntent(ctx context.Context, systemMessage string, userMessage string) (newsArticle.LLMEntitiesAndIntentOutput, error)
+}
+
+// LLMBackend sends a chat to one kind of LLM API.
+type LLMBackend interface {
+	Chat(ctx context.Context, model string, messages []newsArticle.LLMMessage) (newsArticle.LLMChatResult, error)
+}
+
+// LLMService is the LLMProvider of every backend: it bounds the calls in flight and records their usage.
+type LLMService struct {
+	Backend  LLMBackend
+	Logger   *slog.Logger
+	LLMModel string
+	// Usage records the tokens of every call, nil to not record them
+	Usage *LLMUsageService
+
+	// slots bounds the calls in flight at once, nil for no bound
+	slots chan struct{}
+}
+
+// NewLLMService creates the LLM service over the backend. At most 'maxConcurrency' calls are in flight at once,
+// the others wait for a slot; 0 means no limit.
+func NewLLMService(
+	backend LLMBackend,
+	llmModel string,
+	maxConcurrency int,
+	logger *slog.Logger,
+) *LLMService {
+	var slots chan struct{}
+	if maxConcurrency > 0 {
+		slots = make(chan struct{}, maxConcurrency)
+	}
+	return &LLMService{
+		Backend:  backend,
+		Logger:   logger,
+		LLMModel: llmModel,
+		slots:    slots,
+	}
+}
+
+// NewLLMProvider creates the LLM service over the backend chosen by LLM_PROVIDER.
+func NewLLMProvider(config *startup.Config, logger *slog.Logger) (*LLMService, error) {
+	var backend LLMBackend
+	var err error
+	switch config.LLMProvider {
+	case LLM_PROVIDER_OPENROUTER, "":
+		backend, err = NewOpenRouterBackend(config.LLMToken, config.LLMEndpoint)
+	case LLM_PROVIDER_OPENAI:
+		backend = NewOpenAICompatibleBackend(config.LLMToken, config.LLMEndpoint)
+	case LLM_PROVIDER_OLLAMA:
+		backend = NewOllamaBackend(config.LLMEndpoint)
+	case LLM_PROVIDER_FAKE:
+		backend, err = NewFakeBackend(config.LLMFakeResponses)
+	default:
+		err = fmt.Errorf("unknown LLM_PROVIDER %q, expected openrouter, openai, ollama or fake", config.LLMProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewLLMService(backend, config.LLMModel, config.LLMMaxConcurrency, logger), nil
+}
+
+func (llmService *LLMService) Model() string {
+	return llmService.LLMModel
+}
+
+// Chat sends the messages to the backend once a slot is free, and charges the tokens to the request's API key.
+func (llmService *LLMService) Chat(
+	ctx context.Context,
+	messages []newsArticle.LLMMessage,
+) (newsArticle.LLMChatResult, error) {
+	if err := llmService.acquire(ctx); err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+	defer llmService.release()
+
+	result, err := llmService.Backend.Chat(ctx, llmService.LLMModel, messages)
+	if err != nil {
+		return newsArticle.LLMChatResult{}, err
+	}
+	if llmService.Usage != nil {
+		llmService.Usage.Record(ctx, llmService.LLMModel, result.PromptTokens, result.CompletionTokens)
+	}
+	if strings.TrimSpace(result.Content) == "" {
+		return newsArticle.LLMChatResult{}, ErrLLMEmptyResponse
+	}
+	return result, nil
+}
+
+func (llmService *LLMService) GenerateSummary(
+	ctx context.Context,
+	systemMessage string,
+	userMessage string,
+) (string, error) {
+	result, err := llmService.Chat(ctx, []newsArticle.LLMMessage{
+		{Role: newsArticle.LLM_ROLE_SYSTEM, Content: systemMessage},
+		{Role: newsArticle.LLM_ROLE_USER, Content: userMessage},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	llmService.Logger.Debug(fmt.Sprintf("Generated summary response: %v", result.Content))
+	return result.Content, nil
+}
+
+func (llmService *LLMService) ExtractEntitiesAndIntent(
+	ctx context.Context,
+	systemMessage string,
+	userMessage string,
+) (newsArticle.LLMEntitiesAndIntentOutput, error) {
+	result, err := llmService.Chat(ctx, []newsArticle.LLMMessage{
+		{Role: newsArticle.LLM_ROLE_SYSTEM, Content: systemMessage},
+		{Role: newsArticle.LLM_ROLE_USER, Content: userMessage},
+	})
+	if err != nil {
+		return newsArticle.LLMEntitiesAndIntentOutput{}, err
+	}
+	llmService.Logger.Debug(fmt.Sprintf("Extracted entities and intent from user query response: %v", result.Content))
+
+	var llmOutput newsArticle.LLMEntitiesAndIntentOutput
+
+	// Extract JSON from markdown code blocks if present
+	content := result.Content
+	if strings.Contains(content, "```json") {
+		start := strings.Index(content, "```json") + 7
+		end := strings.LastIndex(content, "```")
+		if start < end && end != -1 {
+			content = strings.TrimSpace(content[start:end])
+		}
+	}
+
+	if err := json.Unmarshal([]byte(content), &llmOutput); err != nil {
+		return newsArticle.LLMEntitiesAndIntentOutput{}, err
+	}
+
+	return llmOutput, nil
+}
+
+// acquire waits for a slot to make a call, or for the context to be done.
+func (llmService *LLMService) acquire(ctx context.Context) error {
+	if llmService.slots == nil {
+		return nil
+	}
+	select {
+	case llmService.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (llmService *LLMService) release() {
+	if llmService.slots != nil {
+		<-llmService.slots
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+// ErrInvalidUsageRange is wrapped by the errors about an invalid range of days for the usage report.
+var ErrInvalidUsageRange = errors.New("invalid usage range")
+
+// Which budget of a key is exceeded
+const (
+	LLM_BUDGET_DAILY   = "daily"
+	LLM_BUDGET_MONTHLY = "monthly"
+)
+
+// maxUsageReportDays bounds the range of days of a usage report
+const maxUsageReportDays = 366
+
+// recordUsageTimeout bounds recording the usage of an LLM call, which outlives a cancelled request
+const recordUsageTimeout = 5 * time.Second
+
+type llmDisabledKey struct{}
+
+// WithoutLLM returns a copy of the context in which the news services make no LLM calls:
+// articles are returned without summaries and searches match the query's words.
+func WithoutLLM(ctx context.Context) context.Context {
+	return context.WithValue(ctx, llmDisabledKey{}, true)
+}
+
+func llmDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(llmDisabledKey{}).(bool)
+	return disabled
+}
+
+// LLMUsageService records the tokens and cost of the LLM calls of every API key and checks the keys' budgets.
+// Spends are cached for 'spendCacheTTL', and this instance's calls are added to the cached spends as they are made,
+// so calls made on other instances count towards a budget here after at most that long.
+type LLMUsageService struct {
+	DbInterface   *dbInterface.NewsDbInterface
+	APIKeyService *APIKeyService
+	Logger        *slog.Logger
+	// USD per million tokens, by model
+	PromptPrices     map[string]float64
+	CompletionPrices map[string]float64
+	// DefaultDailyUSD and DefaultMonthlyUSD are the budgets of keys without their own, 0 for none
+	DefaultDailyUSD   float64
+	DefaultMonthlyUSD float64
+
+	spendCache *utils.TTLCache[string, *cachedLLMSpend]
+	// unpriced holds the models without a price, which are warned about once
+	unpriced sync.Map
+}
+
+type cachedLLMSpend struct {
+	mu    sync.Mutex
+	spend newsArticle.LLMSpend
+}
+
+func NewLLMUsageService(
+	dbInterface *dbInterface.NewsDbInterface,
+	apiKeyService *APIKeyService,
+	logger *slog.Logger,
+	config *startup.Config,
+) *LLMUsageService {
+	return &LLMUsageService{
+		DbInterface:       dbInterface,
+		APIKeyService:     apiKeyService,
+		Logger:            logger,
+		PromptPrices:      config.LLMPromptPrices,
+		CompletionPrices:  config.LLMCompletionPrices,
+		DefaultDailyUSD:   config.LLMDailyBudgetUSD,
+		DefaultMonthlyUSD: config.LLMMonthlyBudgetUSD,
+		spendCache:        utils.NewTTLCache[string, *cachedLLMSpend](config.LLMSpendCacheTTL, maxCachedAPIKeys),
+	}
+}
+
+// Record charges an LLM call to the API key of the request. Failures are logged, as the call was made either way.
+func (service *LLMUsageService) Record(ctx context.Context, model string, promptTokens int, completionTokens int) {
+	apiKeyID := reqctx.APIKeyID(ctx)
+	now := time.Now().UTC()
+	usage := newsArticle.LLMUsage{
+		APIKeyID:         apiKeyID,
+		Day:              now.Format(newsArticle.LLM_USAGE_DAY_LAYOUT),
+		Model:            model,
+		Calls:            1,
+		PromptTokens:     int64(promptTokens),
+		CompletionTokens: int64(completionTokens),
+		CostUSD:          service.cost(model, promptTokens, completionTokens),
+		UpdatedAt:        now,
+	}
+
+	if cached, ok := service.spendCache.Get(spendCacheKey(apiKeyID, usage.Day)); ok {
+		cached.mu.Lock()
+		cached.spend.DailyUSD += usage.CostUSD
+		cached.spend.MonthlyUSD += usage.CostUSD
+		cached.mu.Unlock()
+	}
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordUsageTimeout)
+	defer cancel()
+	if err := service.DbInterface.AddLLMUsage(recordCtx, constants.LLM_USAGE, usage); err != nil {
+		service.Logger.Warn("Failed to record LLM usage", "api_key_id", apiKeyID, "model", model, "error", err)
+	}
+}
+
+// ExceededBudget returns which budget of the key is used up, LLM_BUDGET_DAILY or LLM_BUDGET_MONTHLY,
+// or "" when neither is.
+func (service *LLMUsageService) ExceededBudget(ctx context.Context, apiKeyID string) (string, error) {
+	key, err := service.APIKeyService.GetAPIKey(ctx, apiKeyID)
+	if err != nil {
+		return "", err
+	}
+	dailyUSD, monthlyUSD := service.DefaultDailyUSD, service.DefaultMonthlyUSD
+	if key != nil && key.Budget.DailyUSD != nil {
+		dailyUSD = *key.Budget.DailyUSD
+	}
+	if key != nil && key.Budget.MonthlyUSD != nil {
+		monthlyUSD = *key.Budget.MonthlyUSD
+	}
+	if dailyUSD <= 0 && monthlyUSD <= 0 {
+		return "", nil
+	}
+
+	spend, err := service.Spend(ctx, apiKeyID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case dailyUSD > 0 && spend.DailyUSD >= dailyUSD:
+		return LLM_BUDGET_DAILY, nil
+	case monthlyUSD > 0 && spend.MonthlyUSD >= monthlyUSD:
+		return LLM_BUDGET_MONTHLY, nil
+	}
+	return "", nil
+}
+
+// Spend returns what the key spent today and this month, in UTC.
+func (service *LLMUsageService) Spend(ctx context.Context, apiKeyID string) (newsArticle.LLMSpend, error) {
+	now := time.Now().UTC()
+	day := now.Format(newsArticle.LLM_USAGE_DAY_LAYOUT)
+	cacheKey := spendCacheKey(apiKeyID, day)
+	if cached, ok := service.spendCache.Get(cacheKey); ok {
+		cached.mu.Lock()
+		defer cached.mu.Unlock()
+		return cached.spend, nil
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(newsArticle.LLM_USAGE_DAY_LAYOUT)
+	spend, err := service.DbInterface.FindLLMSpend(ctx, constants.LLM_USAGE, apiKeyID, day, monthStart)
+	if err != nil {
+		service.Logger.Error("Failed to fetch LLM spend", "api_key_id", apiKeyID, "error", err)
+		return newsArticle.LLMSpend{}, err
+	}
+	service.spendCache.Set(cacheKey, &cachedLLMSpend{spend: spend})
+	return spend, nil
+}
+
+// UsageReport returns the usage from 'from' to 'to', both "2006-01-02" and today by default,
+// of the key or of all keys when 'apiKeyID' is nil, latest day first.
+func (service *LLMUsageService) UsageReport(
+	ctx context.Context,
+	from string,
+	to string,
+	apiKeyID *string,
+) ([]newsArticle.LLMUsage, error) {
+	service.Logger.Debug("'Service Layer': Fetching LLM usage...")
+
+	today := time.Now().UTC().Format(newsArticle.LLM_USAGE_DAY_LAYOUT)
+	if from == "" {
+		from = today
+	}
+	if to == "" {
+		to = today
+	}
+	fromDay, err := time.Parse(newsArticle.LLM_USAGE_DAY_LAYOUT, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 'from' must be a date like 2006-01-02", ErrInvalidUsageRange)
+	}
+	toDay, err := time.Parse(newsArticle.LLM_USAGE_DAY_LAYOUT, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 'to' must be a date like 2006-01-02", ErrInvalidUsageRange)
+	}
+	if toDay.Before(fromDay) || toDay.Sub(fromDay) > maxUsageReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: 'to' must be after 'from' and at most %d days later", ErrInvalidUsageRange, maxUsageReportDays)
+	}
+
+	usages, err := service.DbInterface.FindLLMUsage(ctx, constants.LLM_USAGE, from, to, apiKeyID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch LLM usage", "error", err)
+		return nil, err
+	}
+	return usages, nil
+}
+
+// cost prices the tokens of a call with the model's prices. Models without prices cost nothing and are warned about.
+func (service *LLMUsageService) cost(model string, promptTokens int, completionTokens int) float64 {
+	promptPrice, hasPromptPrice := service.PromptPrices[model]
+	completionPrice, hasCompletionPrice := service.CompletionPrices[model]
+	if !hasPromptPrice || !hasCompletionPrice {
+		if _, warned := service.unpriced.LoadOrStore(model, true); !warned {
+			service.Logger.Warn("No LLM price configured for the model, its calls are not charged", "model", model)
+		}
+	}
+	return (float64(promptTokens)*promptPrice + float64(completionTokens)*completionPrice) / 1e6
+}
+
+func spendCacheKey(apiKeyID string, day string) string {
+	return apiKeyID + ":" + day
+}
@@ -2,77 +2,239 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
 	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
 	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
 	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 type NewsService struct {
 	DbInterface *dbInterface.NewsDbInterface
 	Logger      *slog.Logger
-	LLMService  *LLMOpenRouterService
+	LLMService  LLMProvider
+	Config      *startup.Config
+	// EventPublisher writes the ingested user events in the background
+	EventPublisher EventPublisher
+	// SummaryQueue generates the summaries of lazy listings in the background, nil to skip them
+	SummaryQueue SummaryQueue
+
+	// profileCache holds the user interest profiles, nil for users known to have no events.
+	// profileLocks serializes building and updating the profile of each user.
+	profileCache *utils.TTLCache[string, *newsArticle.UserProfile]
+	profileLocks utils.KeyedMutex[string]
+
+	// summaryCalls coalesces the concurrent generations of the same summary
+	summaryCalls singleflight.Group
 }
 
 func NewNewsService(
 	dbInterface *dbInterface.NewsDbInterface,
 	logger *slog.Logger,
-	llmService *LLMOpenRouterService,
+	llmService LLMProvider,
+	config *startup.Config,
+	eventPublisher EventPublisher,
 ) *NewsService {
 	return &NewsService{
-		DbInterface: dbInterface,
-		Logger:      logger,
-		LLMService:  llmService,
+		DbInterface:    dbInterface,
+		Logger:         logger,
+		LLMService:     llmService,
+		Config:         config,
+		EventPublisher: eventPublisher,
+		profileCache:   utils.NewTTLCache[string, *newsArticle.UserProfile](config.UserProfileCacheTTL, 10000),
 	}
 }
 
+// nearbyRanking builds the nearby ranking for 'rank' from the configured blend weights.
+// An empty rank means 'distance', like the previous '$near' ordering.
+func (service *NewsService) nearbyRanking(rank string) newsArticle.NearbyRanking {
+	if rank == "" {
+		rank = newsArticle.NEARBY_RANK_DISTANCE
+	}
+	ranking := newsArticle.NearbyRanking{
+		Rank:            rank,
+		DistanceWeight:  service.Config.NearbyBlendDistanceWeight,
+		RecencyWeight:   service.Config.NearbyBlendRecencyWeight,
+		RelevanceWeight: service.Config.NearbyBlendRelevanceWeight,
+		DistanceHalfKm:  service.Config.NearbyBlendDistanceHalfKm,
+		RecencyHalfLife: service.Config.NearbyBlendRecencyHalfLife,
+	}
+	// Both decays are divisors in the blend score
+	if ranking.DistanceHalfKm <= 0 {
+		ranking.DistanceHalfKm = 5
+	}
+	if ranking.RecencyHalfLife <= 0 {
+		ranking.RecencyHalfLife = 24 * time.Hour
+	}
+	return ranking
+}
+
+// ArticleSummaryHelper fills in the LLM summaries and summary statuses of the articles, as set by the
+// request's summary mode (see WithSummaryMode). Stored summaries are reused as long as the article's title
+// and description, the model and the prompt version are unchanged; the others are generated and stored,
+// or queued to the SummaryQueue in lazy mode. Without LLM calls (see WithoutLLM) only the stored summaries
+// are filled in. A failed summary leaves its article without one, and the summaries not generated within
+// SummaryRequestBudget are left pending, so the articles are always returned.
 func (service *NewsService) ArticleSummaryHelper(
 	ctx context.Context,
 	articles []newsArticle.NewsArticleDBResponse,
-) ([]newsArticle.NewsArticleDBResponse, error) {
+) []newsArticle.NewsArticleDBResponse {
+
+	mode := summaryMode(ctx)
+	if mode == newsArticle.SUMMARY_MODE_NONE {
+		return articles
+	}
+
+	model := service.LLMService.Model()
+	promptVersion := constants.ARTICLE_NEWS_SUMMARY_PROMPT_VERSION
+
+	articleIDs := make([]string, len(articles))
+	for i, article := range articles {
+		articleIDs[i] = article.ID
+	}
+	stored, err := service.DbInterface.FindSummaries(ctx, constants.SUMMARIES, articleIDs, model, promptVersion)
+	if err != nil {
+		// The summaries can still be generated
+		service.Logger.Warn("Failed to fetch stored summaries", "error", err)
+		stored = map[string]newsArticle.ArticleSummary{}
+	}
+
+	// Fill in the stored summaries, the missing and stale ones are generated below
+	var pending []int
+	contentHashes := make([]string, len(articles))
+	for i, article := range articles {
+		contentHashes[i] = newsArticle.SummaryContentHash(article.Title, article.Description)
+		if summary, ok := stored[article.ID]; ok && summary.ContentHash == contentHashes[i] {
+			articles[i].LLMSummary = summary.Summary
+			articles[i].SummaryStatus = newsArticle.SUMMARY_STATUS_CACHED
+			continue
+		}
+		switch {
+		case llmDisabled(ctx) || mode == newsArticle.SUMMARY_MODE_CACHED_ONLY:
+			articles[i].SummaryStatus = newsArticle.SUMMARY_STATUS_SKIPPED
+		case mode == newsArticle.SUMMARY_MODE_LAZY:
+			articles[i].SummaryStatus = service.queueSummary(ctx, articles[i])
+		default:
+			pending = append(pending, i)
+		}
+	}
+
+	summaryCtx := ctx
+	if service.Config.SummaryRequestBudget > 0 {
+		var cancel context.CancelFunc
+		summaryCtx, cancel = context.WithTimeout(ctx, service.Config.SummaryRequestBudget)
+		defer cancel()
+	}
+
+	// Generate in parallel, each goroutine writing its own article so the order is kept
+	var group errgroup.Group
+	group.SetLimit(max(1, service.Config.SummaryConcurrency))
+	for _, i := range pending {
+		group.Go(func() error {
+			summary, err := service.generateSummary(summaryCtx, articles[i], contentHashes[i], model, promptVersion)
+			switch {
+			case err == nil:
+				articles[i].LLMSummary = summary
+				articles[i].SummaryStatus = newsArticle.SUMMARY_STATUS_OK
+			case summaryCtx.Err() != nil && errors.Is(err, summaryCtx.Err()):
+				// The generation goes on in the background and stores the summary
+				articles[i].SummaryStatus = newsArticle.SUMMARY_STATUS_PENDING
+			default:
+				service.Logger.Warn("Failed to get article summary", "article_id", articles[i].ID, "error", err)
+				articles[i].SummaryStatus = newsArticle.SUMMARY_STATUS_FAILED
+			}
+			return nil
+		})
+	}
+	group.Wait()
+
+	service.Logger.Debug(fmt.Sprintf("Summary statuses of %d articles: %v", len(articles), newsArticle.SummaryStatusCounts(articles)))
+	return articles
+}
+
+// queueSummary queues the article's summary to be generated in the background and returns its status,
+// skipped when it could not be queued.
+func (service *NewsService) queueSummary(ctx context.Context, article newsArticle.NewsArticleDBResponse) string {
+	if service.SummaryQueue == nil || !service.SummaryQueue.Enqueue(SummaryJob{Article: article, APIKeyID: reqctx.APIKeyID(ctx)}) {
+		return newsArticle.SUMMARY_STATUS_SKIPPED
+	}
+	return newsArticle.SUMMARY_STATUS_PENDING
+}
 
-	systemPrompt := constants.ARTICLE_NEWS_SUMMARY_SYSTEM_PROMPT
+// generateSummary generates and stores the summary of an article. Concurrent requests for the same
+// article, content, model and prompt version share a single LLM call, which is detached from the
+// requests so that one of them being cancelled does not fail the others.
+func (service *NewsService) generateSummary(
+	ctx context.Context,
+	article newsArticle.NewsArticleDBResponse,
+	contentHash string,
+	model string,
+	promptVersion string,
+) (string, error) {
+	key := strings.Join([]string{article.ID, contentHash, model, promptVersion}, "\x00")
+	results := service.summaryCalls.DoChan(key, func() (interface{}, error) {
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), service.Config.SummaryTimeout)
+		defer cancel()
 
-	// Call the LLM service for article summaries
-	for i := range articles {
-		article := articles[i]
 		userPrompt := fmt.Sprintf(constants.ARTICLE_NEWS_SUMMARY_USER_PROMPT, article.Title, article.Description)
+		summary, err := service.LLMService.GenerateSummary(callCtx, constants.ARTICLE_NEWS_SUMMARY_SYSTEM_PROMPT, userPrompt)
+		if err != nil {
+			return "", err
+		}
 
-		summary, err := service.LLMService.GenerateSummary(ctx, systemPrompt, userPrompt)
+		err = service.DbInterface.SaveSummary(callCtx, constants.SUMMARIES, newsArticle.ArticleSummary{
+			ArticleID:     article.ID,
+			ContentHash:   contentHash,
+			Model:         model,
+			PromptVersion: promptVersion,
+			Summary:       summary,
+			CreatedAt:     time.Now(),
+		})
 		if err != nil {
-			service.Logger.Error("Failed to get article summary", "error", err)
-			return nil, err
+			service.Logger.Warn("Failed to store article summary", "article_id", article.ID, "error", err)
 		}
-		articles[i].LLMSummary = summary
-	}
+		return summary, nil
+	})
 
-	return articles, nil
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
 }
 
 func (service *NewsService) LatestNewsService(
 	ctx context.Context,
 	articleLimit int,
+	filters newsArticle.ArticleFilters,
+	preferences *newsArticle.UserPreferences,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	service.Logger.Debug("'Service Layer': Fetching latest news articles...")
 
-	articles, err := service.DbInterface.FindAllArticles(ctx, constants.NEWS, int64(articleLimit))
+	articles, err := service.DbInterface.FindAllArticles(ctx, constants.NEWS, int64(fetchLimitWithFollows(articleLimit, preferences)), filters)
 	if err != nil {
 		service.Logger.Error("Failed to fetch latest news articles", "error", err)
 		return nil, err
 	}
+	articles = boostFollowed(articles, preferences, articleLimit)
 
 	service.Logger.Info(fmt.Sprintf("Fetched %d latest news articles from database and creating summaries...", len(articles)))
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
-	}
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d latest news articles", len(articles)))
 
 	return articles, nil
@@ -82,10 +244,11 @@ func (service *NewsService) CategoryNewsService(
 	ctx context.Context,
 	category string,
 	articleLimit int,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	service.Logger.Debug("'Service Layer': Fetching news articles by category...")
 
-	articles, err := service.DbInterface.FindArticlesByCategory(ctx, constants.NEWS, int64(articleLimit), category)
+	articles, err := service.DbInterface.FindArticlesByCategory(ctx, constants.NEWS, int64(articleLimit), category, filters)
 	if err != nil {
 		service.Logger.Error("Failed to fetch news articles by category", "error", err)
 		return nil, err
@@ -93,11 +256,7 @@ func (service *NewsService) CategoryNewsService(
 	service.Logger.Info(fmt.Sprintf("Fetched %d news articles by category from database and creating summaries...", len(articles)))
 
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
-	}
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d news articles by category", len(articles)))
 
 	return articles, nil
@@ -107,10 +266,11 @@ func (service *NewsService) ScoreNewsService(
 	ctx context.Context,
 	threshold float64,
 	articleLimit int,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	service.Logger.Debug("'Service Layer': Fetching news articles by score...")
 
-	articles, err := service.DbInterface.FindArticlesByScore(ctx, constants.NEWS, int64(articleLimit), threshold)
+	articles, err := service.DbInterface.FindArticlesByScore(ctx, constants.NEWS, int64(articleLimit), threshold, filters)
 	if err != nil {
 		service.Logger.Error("Failed to fetch news articles by score", "error", err)
 		return nil, err
@@ -118,11 +278,7 @@ func (service *NewsService) ScoreNewsService(
 
 	service.Logger.Info(fmt.Sprintf("Fetched %d news articles by score from database and creating summaries...", len(articles)))
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
-	}
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d news articles by score", len(articles)))
 
 	return articles, nil
@@ -132,20 +288,29 @@ func (service *NewsService) SearchNewsService(
 	ctx context.Context,
 	query string,
 	articleLimit int,
+	rank string,
+	filters newsArticle.ArticleFilters,
+	preferences *newsArticle.UserPreferences,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	service.Logger.Debug("'Service Layer': Searching news articles...")
+	fetchLimit := int64(fetchLimitWithFollows(articleLimit, preferences))
 
 	systemMessage := constants.ARTICLE_NEWS_ENTITIES_AND_INTENT_SYSTEM_PROMPT
 	userMessage := fmt.Sprintf(constants.ARTICLE_NEWS_ENTITIES_AND_INTENT_USER_PROMPT, query)
 
-	llmOutput, err := service.LLMService.ExtractEntitiesAndIntent(
-		ctx,
-		systemMessage,
-		userMessage,
-	)
-	if err != nil {
-		service.Logger.Error("Failed to extract entities and intent from user query", "error", err)
-		return nil, err
+	// Without the LLM the query's words are searched as they are
+	llmOutput := newsArticle.LLMEntitiesAndIntentOutput{Intent: "search", Keywords: strings.Fields(query)}
+	var err error
+	if !llmDisabled(ctx) {
+		llmOutput, err = service.LLMService.ExtractEntitiesAndIntent(
+			ctx,
+			systemMessage,
+			userMessage,
+		)
+		if err != nil {
+			service.Logger.Error("Failed to extract entities and intent from user query", "error", err)
+			return nil, err
+		}
 	}
 	service.Logger.Debug("Extracted entities and intent", "entities", llmOutput.Entities, "intent", llmOutput.Intent)
 
@@ -156,7 +321,7 @@ func (service *NewsService) SearchNewsService(
 	switch intent {
 	case "category":
 		// Handle category news intent
-		articles, err = service.DbInterface.FindArticlesByCategory(ctx, constants.NEWS, int64(articleLimit), llmOutput.Entities[0])
+		articles, err = service.DbInterface.FindArticlesByCategory(ctx, constants.NEWS, fetchLimit, llmOutput.Entities[0], filters)
 		if err != nil {
 			service.Logger.Error("Failed to fetch news articles by category", "error", err)
 			return nil, err
@@ -164,7 +329,7 @@ func (service *NewsService) SearchNewsService(
 
 	case "source":
 		// Handle news by source intent
-		articles, err = service.DbInterface.FindArticlesBySource(ctx, constants.NEWS, int64(articleLimit), llmOutput.Entities[0])
+		articles, err = service.DbInterface.FindArticlesBySource(ctx, constants.NEWS, fetchLimit, llmOutput.Entities[0], filters)
 		if err != nil {
 			service.Logger.Error("Failed to fetch news articles by source", "error", err)
 			return nil, err
@@ -192,14 +357,14 @@ func (service *NewsService) SearchNewsService(
 			service.Logger.Error("No valid location found with respect to user query", "locations: ", llmOutput.Entities)
 			service.Logger.Warn("Fallback to 'Normal Search on title and description'")
 			// Fallback to normal search if no valid location found
-			articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, int64(articleLimit), searchableQuery)
+			articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, fetchLimit, searchableQuery, filters)
 			if err != nil {
 				service.Logger.Error("Failed to search news articles", "error", err)
 				return nil, err
 			}
 		} else {
 			// If valid location found, search for nearby articles with latitude and longitude
-			articles, err = service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, int64(articleLimit), latitude, longitude, radius)
+			articles, err = service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, fetchLimit, latitude, longitude, radius, service.nearbyRanking(rank), filters)
 			if err != nil {
 				service.Logger.Error("Failed to fetch nearby news articles", "error", err)
 				return nil, err
@@ -208,7 +373,7 @@ func (service *NewsService) SearchNewsService(
 
 	case "search":
 		// Handle search intent
-		articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, int64(articleLimit), searchableQuery)
+		articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, fetchLimit, searchableQuery, filters)
 		if err != nil {
 			service.Logger.Error("Failed to search news articles", "error", err)
 			return nil, err
@@ -216,20 +381,17 @@ func (service *NewsService) SearchNewsService(
 	default:
 		service.Logger.Warn("Unknown intent, Fallback to normal search", "intent", intent)
 		// Fallback to normal search if intent is unknown
-		articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, int64(articleLimit), searchableQuery)
+		articles, err = service.DbInterface.FindArticlesBySearchQuery(ctx, constants.NEWS, fetchLimit, searchableQuery, filters)
 		if err != nil {
 			service.Logger.Error("Failed to search news articles", "error", err)
 			return nil, err
 		}
 	}
+	articles = boostFollowed(articles, preferences, articleLimit)
 
 	service.Logger.Info(fmt.Sprintf("Fetched %d news articles based on user query and creating summaries...", len(articles)))
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
-	}
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d news articles based on user query", len(articles)))
 
 	return articles, nil
@@ -239,10 +401,11 @@ func (service *NewsService) SourceNewsService(
 	ctx context.Context,
 	source string,
 	articleLimit int,
+	filters newsArticle.ArticleFilters,
 ) ([]newsArticle.NewsArticleDBResponse, error) {
 	service.Logger.Debug("'Service Layer': Fetching news articles by source...")
 
-	articles, err := service.DbInterface.FindArticlesBySource(ctx, constants.NEWS, int64(articleLimit), source)
+	articles, err := service.DbInterface.FindArticlesBySource(ctx, constants.NEWS, int64(articleLimit), source, filters)
 	if err != nil {
 		service.Logger.Error("Failed to fetch news articles by source", "error", err)
 		return nil, err
@@ -250,97 +413,166 @@ func (service *NewsService) SourceNewsService(
 
 	service.Logger.Info(fmt.Sprintf("Fetched %d news articles by source from database and creating summaries...", len(articles)))
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
-	}
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d news articles by source", len(articles)))
 
 	return articles, nil
 }
 
+// NearbyNewsService fetches the articles within 'radius' km of the location.
+// When 'minResults' is set and fewer articles are found, the radius is widened through the configured
+// steps (e.g. 1, 5, 25, 100, 500 km) until enough articles are found or the maximum radius is reached.
+// It returns the articles along with the radius that was finally used.
 func (service *NewsService) NearbyNewsService(
 	ctx context.Context,
 	latitude float64,
 	longitude float64,
 	radius float64,
 	articleLimit int,
-) ([]newsArticle.NewsArticleDBResponse, error) {
+	minResults int,
+	rank string,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, float64, error) {
 	service.Logger.Debug("'Service Layer': Fetching nearby news articles...")
 
-	articles, err := service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, int64(articleLimit), latitude, longitude, radius)
+	ranking := service.nearbyRanking(rank)
+	articles, err := service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, int64(articleLimit), latitude, longitude, radius, ranking, filters)
 	if err != nil {
 		service.Logger.Error("Failed to fetch nearby news articles", "error", err)
-		return nil, err
+		return nil, 0, err
 	}
 
-	service.Logger.Info(fmt.Sprintf("Fetched %d nearby news articles from database and creating summaries...", len(articles)))
-	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
-	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
-		return nil, err
+	for len(articles) < minResults {
+		nextRadius, ok := service.nextNearbyRadius(radius)
+		if !ok {
+			break
+		}
+		service.Logger.Debug(fmt.Sprintf("Found %d of %d nearby news articles within %v km, widening to %v km", len(articles), minResults, radius, nextRadius))
+
+		radius = nextRadius
+		articles, err = service.DbInterface.FindArticlesNearby(ctx, constants.NEWS, int64(articleLimit), latitude, longitude, radius, ranking, filters)
+		if err != nil {
+			service.Logger.Error("Failed to fetch nearby news articles", "error", err)
+			return nil, 0, err
+		}
 	}
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d nearby news articles within %v km from database and creating summaries...", len(articles), radius))
+	// Summarize the articles
+	articles = service.ArticleSummaryHelper(ctx, articles)
 	service.Logger.Info(fmt.Sprintf("Summarized %d nearby news articles", len(articles)))
 
-	return articles, nil
+	return articles, radius, nil
 }
 
-func (service *NewsService) SimulateEventsService(
-	ctx context.Context,
-	userID string,
-	articleID string,
-	eventType string,
-	latitude float64,
-	longitude float64,
-) error {
-	service.Logger.Debug("'Service Layer': Simulating events...")
-
-	event := newsArticle.UserEvent{
-		ArticleID: articleID,
-		EventType: eventType,
-		Latitude:  latitude,
-		Longitude: longitude,
-		Timestamp: time.Now(),
+// nextNearbyRadius returns the first configured radius step above 'radius', capped at the maximum radius.
+// It returns false once the maximum radius has been searched.
+func (service *NewsService) nextNearbyRadius(radius float64) (float64, bool) {
+	maxRadius := service.Config.NearbyMaxRadiusKm
+	if radius >= maxRadius {
+		return radius, false
 	}
+	for _, step := range service.Config.NearbyRadiusStepsKm {
+		if step > radius {
+			return math.Min(step, maxRadius), true
+		}
+	}
+	return maxRadius, true
+}
 
-	// insert in user collection : userEvents
-	err := service.DbInterface.InsertUserEvent(ctx, event)
-	if err != nil {
-		service.Logger.Error("Failed to insert user event", "error", err)
-		return err
+// trendingScoring builds the trend scoring of a window from the configured event weights and half-lives.
+// A window without a configured half-life decays by half every quarter of the window.
+func (service *NewsService) trendingScoring(window string, now time.Time) newsArticle.TrendingScoring {
+	windowDuration, ok := newsArticle.TRENDING_WINDOWS[window]
+	if !ok {
+		window = newsArticle.DEFAULT_TRENDING_WINDOW
+		windowDuration = newsArticle.TRENDING_WINDOWS[window]
+	}
+
+	halfLife, ok := service.Config.TrendingHalfLives[window]
+	if !ok || halfLife <= 0 {
+		halfLife = windowDuration / 4
+	}
+
+	eventWeights := service.Config.TrendingEventWeights
+	if len(eventWeights) == 0 {
+		eventWeights = map[string]float64{"view": 1, "click": 3}
 	}
 
-	service.Logger.Info(fmt.Sprintf("Simulated user event for user %s", userID))
-	return nil
+	return newsArticle.TrendingScoring{
+		Now:          now,
+		Window:       windowDuration,
+		HalfLife:     halfLife,
+		EventWeights: eventWeights,
+	}
 }
 
+// TrendingNewsService fetches the trending articles of the window.
+// A nil location gives global trending, otherwise trending is limited to the location's scope.
+// When snapshots are enabled the latest snapshot is served, and trending is only computed live
+// when there is no recent snapshot for the request. A non-nil 'asOf' serves the snapshot of that time instead.
 func (service *NewsService) TrendingNewsService(
 	ctx context.Context,
 	articleLimit int,
-	latitude float64,
-	longitude float64,
-	radius float64,
-) ([]newsArticle.NewsArticleDBResponse, error) {
+	window string,
+	location *newsArticle.TrendingLocation,
+	filters newsArticle.ArticleFilters,
+	asOf *time.Time,
+	preferences *newsArticle.UserPreferences,
+) ([]newsArticle.NewsArticleDBResponse, *newsArticle.TrendingSource, error) {
 	service.Logger.Debug("'Service Layer': Fetching trending news articles...")
 
-	//TODO: Caching can be implemented here to store and retrieve trending articles efficiently
+	var articles []newsArticle.NewsArticleDBResponse
+	var source *newsArticle.TrendingSource
+	var err error
+	fetchLimit := fetchLimitWithFollows(articleLimit, preferences)
 
-	articles, err := service.DbInterface.FindTrendingArticles(ctx, constants.NEWS, constants.USER_EVENT, int64(articleLimit), latitude, longitude, radius)
-	if err != nil {
-		service.Logger.Error("Failed to fetch trending news articles", "error", err)
-		return nil, err
+	if asOf != nil || (service.Config.TrendingSnapshotsEnabled && (location == nil || location.Scope == newsArticle.TRENDING_SCOPE_EVENTS)) {
+		articles, source, err = service.trendingFromSnapshot(ctx, fetchLimit, window, location, filters, asOf)
+		if err != nil {
+			if !errors.Is(err, ErrTrendingSnapshotNotFound) {
+				service.Logger.Error("Failed to fetch trending snapshot", "error", err)
+			}
+			return nil, nil, err
+		}
+	}
+
+	if source == nil {
+		articles, err = service.DbInterface.FindTrendingArticles(ctx, constants.NEWS, constants.USER_EVENT, int64(fetchLimit), service.trendingScoring(window, time.Now()), location, filters)
+		if err != nil {
+			service.Logger.Error("Failed to fetch trending news articles", "error", err)
+			return nil, nil, err
+		}
+		source = &newsArticle.TrendingSource{Source: newsArticle.TRENDING_SOURCE_LIVE}
 	}
+	articles = boostFollowed(articles, preferences, articleLimit)
 
-	service.Logger.Info(fmt.Sprintf("Fetched %d trending news articles from database and creating summaries...", len(articles)))
+	service.Logger.Info(fmt.Sprintf("Fetched %d trending news articles from %s and creating summaries...", len(articles), source.Source))
 	// Summarize the articles
-	articles, err = service.ArticleSummaryHelper(ctx, articles)
+	articles = service.ArticleSummaryHelper(ctx, articles)
+	service.Logger.Info(fmt.Sprintf("Summarized %d trending news articles", len(articles)))
+
+	return articles, source, nil
+}
+
+func (service *NewsService) WithinNewsService(
+	ctx context.Context,
+	geometry utils.Geometry,
+	articleLimit int,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	service.Logger.Debug("'Service Layer': Fetching news articles within area...")
+
+	articles, err := service.DbInterface.FindArticlesWithin(ctx, constants.NEWS, int64(articleLimit), geometry, filters)
 	if err != nil {
-		service.Logger.Error("Failed to summarize articles", "error", err)
+		service.Logger.Error("Failed to fetch news articles within area", "error", err)
 		return nil, err
 	}
-	service.Logger.Info(fmt.Sprintf("Summarized %d trending news articles", len(articles)))
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d news articles within area from database and creating summaries...", len(articles)))
+	// Summarize the articles
+	articles = service.ArticleSummaryHelper(ctx, articles)
+	service.Logger.Info(fmt.Sprintf("Summarized %d news articles within area", len(articles)))
 
 	return articles, nil
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+)
+
+// slowBackend answers like its fake backend, except for the chats containing its 'slow' text, which it
+// holds until they are cancelled.
+type slowBackend struct {
+	*FakeBackend
+	slow string
+}
+
+func (backend slowBackend) Chat(ctx context.Context, model string, messages []newsArticle.LLMMessage) (newsArticle.LLMChatResult, error) {
+	for _, message := range messages {
+		if strings.Contains(message.Content, backend.slow) {
+			<-ctx.Done()
+			return newsArticle.LLMChatResult{}, ctx.Err()
+		}
+	}
+	return backend.FakeBackend.Chat(ctx, model, messages)
+}
+
+func TestArticleSummaryHelperStatuses(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("statuses", func(mt *mtest.T) {
+		fake, err := NewFakeBackendFromResponses(FakeLLMResponses{
+			Rules:   append([]FakeLLMRule{{Match: "Broken", Responses: []string{""}}}, DefaultFakeLLMResponses.Rules...),
+			Default: DefaultFakeLLMResponses.Default,
+		})
+		if err != nil {
+			mt.Fatal(err)
+		}
+		logger := discardLogger()
+		llm := NewLLMService(slowBackend{FakeBackend: fake, slow: "Slow"}, "fake-model", 0, logger)
+		service := NewNewsService(
+			&dbInterface.NewsDbInterface{DB: mt.DB, Logger: logger},
+			logger,
+			llm,
+			&startup.Config{
+				SummaryRequestBudget: 100 * time.Millisecond,
+				SummaryConcurrency:   4,
+				SummaryTimeout:       time.Second,
+				UserProfileCacheTTL:  time.Minute,
+			},
+			nil,
+		)
+
+		articles := []newsArticle.NewsArticleDBResponse{
+			{ID: "fresh", Title: "Rain in Paris", Description: "Heavy rain fell over Paris."},
+			{ID: "stored", Title: "Stored story", Description: "Summarized before."},
+			{ID: "stale", Title: "Edited story", Description: "Edited since its summary."},
+			{ID: "broken", Title: "Broken story", Description: "The LLM answers nothing."},
+			{ID: "slow", Title: "Slow story", Description: "The LLM takes too long."},
+		}
+		storedSummary := func(articleID string, contentHash string) bson.D {
+			return bson.D{
+				{Key: "article_id", Value: articleID},
+				{Key: "content_hash", Value: contentHash},
+				{Key: "model", Value: "fake-model"},
+				{Key: "prompt_version", Value: constants.ARTICLE_NEWS_SUMMARY_PROMPT_VERSION},
+				{Key: "summary", Value: "The stored summary"},
+			}
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+constants.SUMMARIES, mtest.FirstBatch,
+			storedSummary("stored", newsArticle.SummaryContentHash("Stored story", "Summarized before.")),
+			storedSummary("stale", newsArticle.SummaryContentHash("Edited story", "Before the edit.")),
+		))
+		// Storing the generated summaries fails without more mock responses, which is only logged
+
+		articles = service.ArticleSummaryHelper(context.Background(), articles)
+
+		want := map[string]string{
+			"fresh":  newsArticle.SUMMARY_STATUS_OK,
+			"stored": newsArticle.SUMMARY_STATUS_CACHED,
+			"stale":  newsArticle.SUMMARY_STATUS_OK,
+			"broken": newsArticle.SUMMARY_STATUS_FAILED,
+			"slow":   newsArticle.SUMMARY_STATUS_PENDING,
+		}
+		for _, article := range articles {
+			if article.SummaryStatus != want[article.ID] {
+				mt.Errorf("%s: status = %q, want %q", article.ID, article.SummaryStatus, want[article.ID])
+			}
+		}
+		if summary := articles[0].LLMSummary; !strings.HasPrefix(summary, "Rain in Paris: Heavy rain") {
+			mt.Errorf("fresh: summary = %q, want the generated one", summary)
+		}
+		if summary := articles[1].LLMSummary; summary != "The stored summary" {
+			mt.Errorf("stored: summary = %q, want the stored one", summary)
+		}
+		if summary := articles[2].LLMSummary; summary == "The stored summary" {
+			mt.Error("stale: got the stored summary of the previous content")
+		}
+		for _, article := range articles[3:] {
+			if article.LLMSummary != "" {
+				mt.Errorf("%s: summary = %q, want none", article.ID, article.LLMSummary)
+			}
+		}
+	})
+}
+
+func TestArticleSummaryHelperModes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{newsArticle.SUMMARY_MODE_NONE, ""},
+		{newsArticle.SUMMARY_MODE_CACHED_ONLY, newsArticle.SUMMARY_STATUS_SKIPPED},
+		// Skipped without a queue
+		{newsArticle.SUMMARY_MODE_LAZY, newsArticle.SUMMARY_STATUS_SKIPPED},
+	}
+	for _, test := range tests {
+		mt.Run(test.mode, func(mt *mtest.T) {
+			logger := discardLogger()
+			llm := newFakeLLMService(t, DefaultFakeLLMResponses)
+			service := NewNewsService(
+				&dbInterface.NewsDbInterface{DB: mt.DB, Logger: logger},
+				logger,
+				llm,
+				&startup.Config{SummaryConcurrency: 1, SummaryTimeout: time.Second, UserProfileCacheTTL: time.Minute},
+				nil,
+			)
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "db."+constants.SUMMARIES, mtest.FirstBatch))
+
+			ctx := WithSummaryMode(context.Background(), test.mode)
+			articles := service.ArticleSummaryHelper(ctx, []newsArticle.NewsArticleDBResponse{{ID: "fresh", Title: "Title"}})
+			if articles[0].SummaryStatus != test.want || articles[0].LLMSummary != "" {
+				mt.Errorf("status = %q, summary = %q, want %q and none", articles[0].SummaryStatus, articles[0].LLMSummary, test.want)
+			}
+		})
+	}
+}
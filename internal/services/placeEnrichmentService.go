@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+type PlaceEnrichmentService struct {
+	DbInterface *dbInterface.NewsDbInterface
+	Logger      *slog.Logger
+	Geocoder    utils.ReverseGeocoder
+}
+
+func NewPlaceEnrichmentService(
+	dbInterface *dbInterface.NewsDbInterface,
+	logger *slog.Logger,
+	geocoder utils.ReverseGeocoder,
+) *PlaceEnrichmentService {
+	return &PlaceEnrichmentService{
+		DbInterface: dbInterface,
+		Logger:      logger,
+		Geocoder:    geocoder,
+	}
+}
+
+// BackfillPlaces reverse geocodes the stored articles in batches and saves city, region and country code on them.
+// Articles that already have a place are skipped unless 'overwrite' is set.
+// It returns how many articles were enriched and how many could not be resolved.
+func (service *PlaceEnrichmentService) BackfillPlaces(
+	ctx context.Context,
+	batchSize int,
+	overwrite bool,
+) (int, int, error) {
+	service.Logger.Debug("'Service Layer': Backfilling article places...")
+
+	enriched, unresolved := 0, 0
+	afterID := ""
+	for {
+		articles, err := service.DbInterface.FindArticlesForPlaceEnrichment(ctx, constants.NEWS, int64(batchSize), afterID, overwrite)
+		if err != nil {
+			service.Logger.Error("Failed to fetch articles for place enrichment", "error", err)
+			return enriched, unresolved, err
+		}
+		if len(articles) == 0 {
+			break
+		}
+
+		places := make(map[string]utils.Place, len(articles))
+		for _, article := range articles {
+			place, err := service.Geocoder.ReverseGeocode(article.Latitude, article.Longitude)
+			if err != nil {
+				if !errors.Is(err, utils.ErrPlaceNotFound) {
+					service.Logger.Warn("Failed to reverse geocode article", "article_id", article.ID, "error", err)
+				}
+				unresolved++
+				continue
+			}
+			places[article.ID] = place
+		}
+
+		if err := service.DbInterface.UpdateArticlePlaces(ctx, constants.NEWS, places); err != nil {
+			service.Logger.Error("Failed to update article places", "error", err)
+			return enriched, unresolved, err
+		}
+		enriched += len(places)
+		afterID = articles[len(articles)-1].ID
+
+		service.Logger.Info(fmt.Sprintf("Enriched %d articles with places so far (%d unresolved)", enriched, unresolved))
+	}
+
+	return enriched, unresolved, nil
+}
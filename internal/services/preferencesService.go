@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+// ErrInvalidPreference is wrapped by the errors about invalid follows and mutes.
+var ErrInvalidPreference = errors.New("invalid preference")
+
+const (
+	// maxPreferenceValues bounds every follow and mute list
+	maxPreferenceValues = 100
+	// defaultPlaceRadiusKm is the radius of a followed place saved without one
+	defaultPlaceRadiusKm = 25
+	maxPlaceRadiusKm     = 500
+	// followBoostFetchFactor is how many more articles are fetched than requested so that the followed ones can be moved up
+	followBoostFetchFactor = 3
+)
+
+func (service *NewsService) GetUserPreferencesService(ctx context.Context, userID string) (*newsArticle.UserPreferences, error) {
+	service.Logger.Debug("'Service Layer': Fetching user preferences...")
+
+	preferences, err := service.DbInterface.FindUserPreferences(ctx, constants.USERS, userID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch user preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &newsArticle.UserPreferences{UserID: userID}
+	}
+	return withEmptyLists(preferences), nil
+}
+
+// ReplaceUserPreferencesService replaces all the preferences of the user at once, e.g. after onboarding.
+func (service *NewsService) ReplaceUserPreferencesService(
+	ctx context.Context,
+	userID string,
+	preferences newsArticle.UserPreferences,
+) (*newsArticle.UserPreferences, error) {
+	service.Logger.Debug("'Service Layer': Replacing user preferences...")
+
+	normalized := newsArticle.UserPreferences{UserID: userID, UpdatedAt: time.Now()}
+	lists := []struct {
+		kind   string
+		values []string
+		target *[]string
+	}{
+		{"followed categories", preferences.Categories, &normalized.Categories},
+		{"followed sources", preferences.Sources, &normalized.Sources},
+		{"muted sources", preferences.MutedSources, &normalized.MutedSources},
+		{"muted keywords", preferences.MutedKeywords, &normalized.MutedKeywords},
+	}
+	for _, list := range lists {
+		values, err := normalizePreferenceValues(list.kind, list.values)
+		if err != nil {
+			return nil, err
+		}
+		*list.target = values
+	}
+
+	names := map[string]bool{}
+	for _, place := range preferences.Places {
+		place, err := normalizeSavedPlace(place)
+		if err != nil {
+			return nil, err
+		}
+		if names[place.Name] {
+			return nil, fmt.Errorf("%w: place %q is listed twice", ErrInvalidPreference, place.Name)
+		}
+		names[place.Name] = true
+		normalized.Places = append(normalized.Places, place)
+	}
+	if len(normalized.Places) > maxPreferenceValues {
+		return nil, fmt.Errorf("%w: at most %d followed places are allowed", ErrInvalidPreference, maxPreferenceValues)
+	}
+
+	if err := service.DbInterface.ReplaceUserPreferences(ctx, constants.USERS, normalized); err != nil {
+		service.Logger.Error("Failed to replace user preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+	return withEmptyLists(&normalized), nil
+}
+
+// FollowService follows a category or source ('kind'), or unfollows it when 'follow' is false.
+// Unfollowing also accepts the 'places' kind, with the place name as the value.
+func (service *NewsService) FollowService(
+	ctx context.Context,
+	userID string,
+	kind string,
+	value string,
+	follow bool,
+) (*newsArticle.UserPreferences, error) {
+	service.Logger.Debug("'Service Layer': Updating user follows...")
+
+	if kind == newsArticle.PREFERENCE_PLACES && !follow {
+		return service.updatePreferences(userID, func() (*newsArticle.UserPreferences, error) {
+			return service.DbInterface.RemoveFollowedPlace(ctx, constants.USERS, userID, strings.TrimSpace(value))
+		})
+	}
+	field, ok := newsArticle.FOLLOW_FIELDS[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: only categories and sources can be followed by value, places are saved with a body", ErrInvalidPreference)
+	}
+	return service.updatePreferenceList(ctx, userID, field, kind, value, follow)
+}
+
+// MuteService mutes a source or keyword ('kind'), or unmutes it when 'mute' is false.
+func (service *NewsService) MuteService(
+	ctx context.Context,
+	userID string,
+	kind string,
+	value string,
+	mute bool,
+) (*newsArticle.UserPreferences, error) {
+	service.Logger.Debug("'Service Layer': Updating user mutes...")
+
+	field, ok := newsArticle.MUTE_FIELDS[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: only sources and keywords can be muted", ErrInvalidPreference)
+	}
+	return service.updatePreferenceList(ctx, userID, field, kind, value, mute)
+}
+
+// FollowPlaceService follows the place, replacing a followed place with the same name.
+func (service *NewsService) FollowPlaceService(
+	ctx context.Context,
+	userID string,
+	place newsArticle.SavedPlace,
+) (*newsArticle.UserPreferences, error) {
+	service.Logger.Debug("'Service Layer': Following place...")
+
+	place, err := normalizeSavedPlace(place)
+	if err != nil {
+		return nil, err
+	}
+	current, err := service.GetUserPreferencesService(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Places) >= maxPreferenceValues {
+		return nil, fmt.Errorf("%w: at most %d followed places are allowed", ErrInvalidPreference, maxPreferenceValues)
+	}
+
+	return service.updatePreferences(userID, func() (*newsArticle.UserPreferences, error) {
+		return service.DbInterface.SaveFollowedPlace(ctx, constants.USERS, userID, place)
+	})
+}
+
+func (service *NewsService) updatePreferenceList(
+	ctx context.Context,
+	userID string,
+	field string,
+	kind string,
+	value string,
+	add bool,
+) (*newsArticle.UserPreferences, error) {
+	values, err := normalizePreferenceValues(kind, []string{value})
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: the value must not be empty", ErrInvalidPreference)
+	}
+
+	if !add {
+		return service.updatePreferences(userID, func() (*newsArticle.UserPreferences, error) {
+			return service.DbInterface.RemoveUserPreference(ctx, constants.USERS, userID, field, values[0])
+		})
+	}
+	current, err := service.GetUserPreferencesService(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Adding a value which is already listed changes nothing, so it is allowed at the limit
+	list := preferenceList(current, field)
+	if len(list) >= maxPreferenceValues && !slices.Contains(list, values[0]) {
+		return nil, fmt.Errorf("%w: at most %d %s are allowed", ErrInvalidPreference, maxPreferenceValues, kind)
+	}
+
+	return service.updatePreferences(userID, func() (*newsArticle.UserPreferences, error) {
+		return service.DbInterface.AddUserPreference(ctx, constants.USERS, userID, field, values[0])
+	})
+}
+
+// preferenceList returns the list stored in 'field', one of the FOLLOW_FIELDS and MUTE_FIELDS.
+func preferenceList(preferences *newsArticle.UserPreferences, field string) []string {
+	switch field {
+	case newsArticle.FOLLOW_FIELDS[newsArticle.PREFERENCE_CATEGORIES]:
+		return preferences.Categories
+	case newsArticle.FOLLOW_FIELDS[newsArticle.PREFERENCE_SOURCES]:
+		return preferences.Sources
+	case newsArticle.MUTE_FIELDS[newsArticle.PREFERENCE_SOURCES]:
+		return preferences.MutedSources
+	case newsArticle.MUTE_FIELDS[newsArticle.PREFERENCE_KEYWORDS]:
+		return preferences.MutedKeywords
+	}
+	return nil
+}
+
+func (service *NewsService) updatePreferences(
+	userID string,
+	update func() (*newsArticle.UserPreferences, error),
+) (*newsArticle.UserPreferences, error) {
+	preferences, err := update()
+	if err != nil {
+		service.Logger.Error("Failed to update user preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+	return withEmptyLists(preferences), nil
+}
+
+// ApplyUserPreferences adds the user's mutes to the filters and, for the "following" tab, their follows.
+// It returns the preferences, nil when the request has no user or the user has none.
+func (service *NewsService) ApplyUserPreferences(
+	ctx context.Context,
+	userID string,
+	following bool,
+	filters *newsArticle.ArticleFilters,
+) (*newsArticle.UserPreferences, error) {
+	if userID == "" {
+		return nil, nil
+	}
+
+	preferences, err := service.DbInterface.FindUserPreferences(ctx, constants.USERS, userID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch user preferences", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if following {
+		// A user without follows has an empty following tab
+		filters.Following = &newsArticle.Follows{}
+	}
+	if preferences == nil {
+		return nil, nil
+	}
+
+	filters.MutedSources = preferences.MutedSources
+	filters.MutedKeywords = preferences.MutedKeywords
+	if following {
+		filters.Following = &preferences.Follows
+	}
+	return preferences, nil
+}
+
+// fetchLimitWithFollows is how many articles to fetch for 'articleLimit' results
+// so that boostFollowed has more followed articles to move up.
+func fetchLimitWithFollows(articleLimit int, preferences *newsArticle.UserPreferences) int {
+	if preferences == nil || preferences.Follows.IsEmpty() {
+		return articleLimit
+	}
+	return articleLimit * followBoostFetchFactor
+}
+
+// boostFollowed moves the articles matching one of the user's follows before the others, keeping the order
+// within both groups, and keeps the first 'articleLimit', all of them when it is not positive.
+func boostFollowed(
+	articles []newsArticle.NewsArticleDBResponse,
+	preferences *newsArticle.UserPreferences,
+	articleLimit int,
+) []newsArticle.NewsArticleDBResponse {
+	if preferences != nil && !preferences.Follows.IsEmpty() {
+		sort.SliceStable(articles, func(i, j int) bool {
+			return matchesFollows(articles[i], preferences.Follows) && !matchesFollows(articles[j], preferences.Follows)
+		})
+	}
+	if articleLimit > 0 && len(articles) > articleLimit {
+		articles = articles[:articleLimit]
+	}
+	return articles
+}
+
+func matchesFollows(article newsArticle.NewsArticleDBResponse, follows newsArticle.Follows) bool {
+	for _, category := range article.Category {
+		for _, followed := range follows.Categories {
+			if strings.EqualFold(category, followed) {
+				return true
+			}
+		}
+	}
+	for _, followed := range follows.Sources {
+		if strings.EqualFold(article.SourceName, followed) {
+			return true
+		}
+	}
+	for _, place := range follows.Places {
+		if utils.HaversineKm(place.Latitude, place.Longitude, article.Latitude, article.Longitude) <= place.RadiusKm {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePreferenceValues trims, lowercases and de-duplicates the values and checks the list size.
+func normalizePreferenceValues(kind string, values []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	if len(normalized) > maxPreferenceValues {
+		return nil, fmt.Errorf("%w: at most %d %s are allowed", ErrInvalidPreference, maxPreferenceValues, kind)
+	}
+	return normalized, nil
+}
+
+func normalizeSavedPlace(place newsArticle.SavedPlace) (newsArticle.SavedPlace, error) {
+	place.Name = strings.TrimSpace(place.Name)
+	if place.Name == "" {
+		return place, fmt.Errorf("%w: a followed place needs a name", ErrInvalidPreference)
+	}
+	if place.Latitude < -90 || place.Latitude > 90 || place.Longitude < -180 || place.Longitude > 180 {
+		return place, fmt.Errorf("%w: place %q has an invalid latitude or longitude", ErrInvalidPreference, place.Name)
+	}
+	if place.RadiusKm == 0 {
+		place.RadiusKm = defaultPlaceRadiusKm
+	}
+	if place.RadiusKm < 0 || place.RadiusKm > maxPlaceRadiusKm {
+		return place, fmt.Errorf("%w: place radius_km must be between 0 and %d", ErrInvalidPreference, maxPlaceRadiusKm)
+	}
+	return place, nil
+}
+
+// withEmptyLists replaces missing lists with empty ones so that they are never null in responses.
+func withEmptyLists(preferences *newsArticle.UserPreferences) *newsArticle.UserPreferences {
+	for _, list := range []*[]string{&preferences.Categories, &preferences.Sources, &preferences.MutedSources, &preferences.MutedKeywords} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if preferences.Places == nil {
+		preferences.Places = []newsArticle.SavedPlace{}
+	}
+	return preferences
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+)
+
+// ExcludeSeenArticles adds the articles in the user's read history to the excluded articles of the filters,
+// so that the listings skip them and fill the limit with the next articles instead.
+func (service *NewsService) ExcludeSeenArticles(ctx context.Context, userID string, filters *newsArticle.ArticleFilters) error {
+	service.Logger.Debug("'Service Layer': Excluding seen articles...")
+
+	articleIDs, err := service.readArticleIDs(ctx, userID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch read history", "user_id", userID, "error", err)
+		return err
+	}
+	filters.ExcludeArticleIDs = append(filters.ExcludeArticleIDs, articleIDs...)
+	return nil
+}
+
+// ReadHistoryService fetches the articles the user read most recently, latest first.
+// Articles deleted since are skipped, and a limit which is not positive returns the whole history.
+func (service *NewsService) ReadHistoryService(
+	ctx context.Context,
+	userID string,
+	articleLimit int,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	service.Logger.Debug("'Service Layer': Fetching read history...")
+
+	articleIDs, err := service.readArticleIDs(ctx, userID)
+	if err != nil {
+		service.Logger.Error("Failed to fetch read history", "user_id", userID, "error", err)
+		return nil, err
+	}
+	if articleLimit > 0 && len(articleIDs) > articleLimit {
+		articleIDs = articleIDs[len(articleIDs)-articleLimit:]
+	}
+
+	found, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, newsArticle.ArticleFilters{})
+	if err != nil {
+		service.Logger.Error("Failed to fetch read articles", "error", err)
+		return nil, err
+	}
+	byID := make(map[string]newsArticle.NewsArticleDBResponse, len(found))
+	for _, article := range found {
+		byID[article.ID] = article
+	}
+
+	articles := []newsArticle.NewsArticleDBResponse{}
+	for i := len(articleIDs) - 1; i >= 0; i-- {
+		if article, ok := byID[articleIDs[i]]; ok {
+			articles = append(articles, article)
+		}
+	}
+
+	service.Logger.Info(fmt.Sprintf("Fetched %d read articles of user %s", len(articles), userID))
+	return articles, nil
+}
+
+// UpdateReadHistories adds the articles of newly written read events to the histories of their users.
+// Users without a history yet get one built from their events, which already contain the new ones.
+func (service *NewsService) UpdateReadHistories(ctx context.Context, events []newsArticle.UserEvent) error {
+	service.Logger.Debug("'Service Layer': Updating read histories...")
+
+	byUser := map[string][]newsArticle.UserEvent{}
+	for _, event := range events {
+		if event.UserID != "" && isReadEvent(event.EventType) {
+			byUser[event.UserID] = append(byUser[event.UserID], event)
+		}
+	}
+
+	for userID, userEvents := range byUser {
+		history, err := service.DbInterface.FindReadHistory(ctx, constants.READ_HISTORY, userID)
+		if err != nil {
+			return err
+		}
+		if history == nil {
+			if _, err := service.buildReadHistory(ctx, userID); err != nil {
+				return err
+			}
+			continue
+		}
+		sort.SliceStable(userEvents, func(i, j int) bool { return userEvents[i].Timestamp.Before(userEvents[j].Timestamp) })
+		if err := service.DbInterface.AddToReadHistory(ctx, constants.READ_HISTORY, userID, eventArticleIDs(userEvents), service.Config.ReadHistorySize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readArticleIDs returns the user's read articles, oldest first, building their history on first use.
+func (service *NewsService) readArticleIDs(ctx context.Context, userID string) ([]string, error) {
+	history, err := service.DbInterface.FindReadHistory(ctx, constants.READ_HISTORY, userID)
+	if err != nil {
+		return nil, err
+	}
+	if history != nil {
+		return history.ArticleIDs, nil
+	}
+	return service.buildReadHistory(ctx, userID)
+}
+
+// buildReadHistory stores the read history of the user from their latest read events and returns its articles.
+func (service *NewsService) buildReadHistory(ctx context.Context, userID string) ([]string, error) {
+	events, err := service.DbInterface.FindUserEvents(ctx, constants.USER_EVENT, userID, newsArticle.READ_EVENT_TYPES, time.Time{}, int64(service.Config.ReadHistorySize))
+	if err != nil {
+		return nil, err
+	}
+	articleIDs := eventArticleIDs(events)
+	if len(articleIDs) == 0 {
+		return articleIDs, nil
+	}
+	if err := service.DbInterface.AddToReadHistory(ctx, constants.READ_HISTORY, userID, articleIDs, service.Config.ReadHistorySize); err != nil {
+		return nil, err
+	}
+	return articleIDs, nil
+}
+
+// eventArticleIDs returns the distinct articles of the events, oldest first, each at its latest read.
+func eventArticleIDs(events []newsArticle.UserEvent) []string {
+	latest := map[string]int{}
+	for i, event := range events {
+		latest[event.ArticleID] = i
+	}
+	articleIDs := []string{}
+	for i, event := range events {
+		if latest[event.ArticleID] == i {
+			articleIDs = append(articleIDs, event.ArticleID)
+		}
+	}
+	return articleIDs
+}
+
+func isReadEvent(eventType string) bool {
+	for _, readType := range newsArticle.READ_EVENT_TYPES {
+		if eventType == readType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
+)
+
+// SUMMARY_PREGEN_JOB names the checkpoint of the background summary pre-generation.
+const SUMMARY_PREGEN_JOB = "summary-pregen"
+
+const (
+	// summaryBackfillBatchSize is how many articles are checked for a current summary at a time
+	summaryBackfillBatchSize = 200
+	// maxSummaryRetryBackoff caps the wait before a failed summary is retried
+	maxSummaryRetryBackoff = 24 * time.Hour
+)
+
+// SummaryBackfillOptions select the articles PregenerateSummaries generates the summaries of.
+type SummaryBackfillOptions struct {
+	// Job names the checkpoint which lets an interrupted pass resume, "" for none
+	Job      string
+	Since    time.Time // Articles published since, all when zero
+	Category string
+	// MaxGenerated stops the run after generating this many summaries, 0 for no limit
+	MaxGenerated int
+	// PerMinute is the most summaries generated per minute, 0 for no limit
+	PerMinute float64
+	// DryRun only counts the summaries to generate
+	DryRun bool
+}
+
+// SummaryBackfillResult counts what a PregenerateSummaries run did.
+type SummaryBackfillResult struct {
+	Checked    int // Articles checked for a current summary
+	ToGenerate int // Articles without a current summary which were due
+	Generated  int
+	Failed     int
+	Deferred   int  // Articles whose earlier failure is not due for a retry yet
+	Complete   bool // The pass went through every selected article
+}
+
+// PregenerateSummaries generates and stores the missing and stale summaries of the selected articles, so that
+// listings find them stored: the trending articles first, then the newest ones, then on from the job's checkpoint
+// through older articles. The checkpoint is saved after every batch and removed once the pass is complete.
+// A failed summary is retried on a later run, waiting SummaryRetryBackoff longer after every attempt.
+func (service *NewsService) PregenerateSummaries(
+	ctx context.Context,
+	options SummaryBackfillOptions,
+) (*SummaryBackfillResult, error) {
+	service.Logger.Debug("'Service Layer': Pre-generating article summaries...")
+
+	result := &SummaryBackfillResult{}
+	filters := newsArticle.ArticleFilters{Category: options.Category, PublishedFrom: options.Since}
+	pregen := &summaryPregen{
+		service: service,
+		options: options,
+		result:  result,
+		checked: map[string]bool{},
+	}
+	if options.PerMinute > 0 {
+		pregen.limiter = ratelimit.NewMemoryStore()
+		pregen.limit = ratelimit.Limit{Rate: options.PerMinute / 60, Burst: 1}
+	}
+
+	trending, err := service.trendingArticlesForSummaries(ctx, filters)
+	if err != nil {
+		// The pass still finds them
+		service.Logger.Warn("Failed to fetch trending articles for summaries", "error", err)
+	}
+	if err := pregen.summarize(ctx, trending); err != nil {
+		return result, err
+	}
+
+	var checkpoint *newsArticle.SummaryCheckpoint
+	if options.Job != "" && !options.DryRun {
+		checkpoint, err = service.DbInterface.FindSummaryCheckpoint(ctx, constants.JOB_CHECKPOINTS, options.Job)
+		if err != nil {
+			service.Logger.Error("Failed to fetch summary checkpoint", "error", err)
+			return result, err
+		}
+	}
+	if checkpoint != nil {
+		// The articles published since the pass started are not behind the checkpoint
+		newest, err := service.DbInterface.FindArticlesForSummaries(ctx, constants.NEWS, summaryBackfillBatchSize, filters, nil)
+		if err != nil {
+			service.Logger.Error("Failed to fetch articles for summaries", "error", err)
+			return result, err
+		}
+		if err := pregen.summarize(ctx, newest); err != nil {
+			return result, err
+		}
+	}
+
+	after := checkpoint
+	for !pregen.done() {
+		articles, err := service.DbInterface.FindArticlesForSummaries(ctx, constants.NEWS, summaryBackfillBatchSize, filters, after)
+		if err != nil {
+			service.Logger.Error("Failed to fetch articles for summaries", "error", err)
+			return result, err
+		}
+		if len(articles) == 0 {
+			result.Complete = true
+			break
+		}
+
+		if err := pregen.summarize(ctx, articles); err != nil {
+			return result, err
+		}
+		if pregen.done() {
+			// The rest of the batch is checked again on the next run
+			break
+		}
+
+		last := articles[len(articles)-1]
+		publicationDate, _ := last.PublicationDate.(string)
+		after = &newsArticle.SummaryCheckpoint{
+			Job:             options.Job,
+			PublicationDate: publicationDate,
+			ArticleID:       last.ID,
+			UpdatedAt:       time.Now(),
+		}
+		if options.Job != "" && !options.DryRun {
+			if err := service.DbInterface.SaveSummaryCheckpoint(ctx, constants.JOB_CHECKPOINTS, *after); err != nil {
+				service.Logger.Warn("Failed to save summary checkpoint", "error", err)
+			}
+		}
+		service.Logger.Info(fmt.Sprintf("Checked %d articles for summaries so far, generated %d (%d failed)", result.Checked, result.Generated, result.Failed))
+	}
+
+	if result.Complete && options.Job != "" && !options.DryRun {
+		if err := service.DbInterface.DeleteSummaryCheckpoint(ctx, constants.JOB_CHECKPOINTS, options.Job); err != nil {
+			service.Logger.Warn("Failed to delete summary checkpoint", "error", err)
+		}
+	}
+	return result, nil
+}
+
+// trendingArticlesForSummaries returns the articles of the latest global trending snapshots of the default
+// window which match the filters, most trending first.
+func (service *NewsService) trendingArticlesForSummaries(
+	ctx context.Context,
+	filters newsArticle.ArticleFilters,
+) ([]newsArticle.NewsArticleDBResponse, error) {
+	category := strings.ToLower(strings.TrimSpace(filters.Category))
+	snapshots, _, err := service.DbInterface.FindTrendingSnapshots(ctx, constants.TRENDING_SNAPSHOTS, newsArticle.DEFAULT_TRENDING_WINDOW, category, []string{""}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _ := mergeSnapshotEntries(snapshots, 0)
+	rank := make(map[string]int, len(entries))
+	articleIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		rank[entry.ArticleID] = i
+		articleIDs[i] = entry.ArticleID
+	}
+	articles, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, filters)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(articles, func(i, j int) bool {
+		return rank[articles[i].ID] < rank[articles[j].ID]
+	})
+	return articles, nil
+}
+
+// summaryPregen is the state of one PregenerateSummaries run.
+type summaryPregen struct {
+	service *NewsService
+	options SummaryBackfillOptions
+	result  *SummaryBackfillResult
+	limiter ratelimit.Store
+	limit   ratelimit.Limit
+	// checked holds the articles already checked in this run, as the trending and newest ones come again
+	checked map[string]bool
+}
+
+func (pregen *summaryPregen) done() bool {
+	return pregen.options.MaxGenerated > 0 && pregen.result.Generated >= pregen.options.MaxGenerated
+}
+
+// summarize generates the due summaries of the articles which have no current one, in order.
+func (pregen *summaryPregen) summarize(ctx context.Context, articles []newsArticle.NewsArticleDBResponse) error {
+	service := pregen.service
+	model := service.LLMService.Model()
+	promptVersion := constants.ARTICLE_NEWS_SUMMARY_PROMPT_VERSION
+
+	var unchecked []newsArticle.NewsArticleDBResponse
+	for _, article := range articles {
+		if !pregen.checked[article.ID] {
+			pregen.checked[article.ID] = true
+			unchecked = append(unchecked, article)
+		}
+	}
+	if len(unchecked) == 0 {
+		return nil
+	}
+	pregen.result.Checked += len(unchecked)
+
+	articleIDs := make([]string, len(unchecked))
+	for i, article := range unchecked {
+		articleIDs[i] = article.ID
+	}
+	stored, err := service.DbInterface.FindSummaries(ctx, constants.SUMMARIES, articleIDs, model, promptVersion)
+	if err != nil {
+		service.Logger.Error("Failed to fetch stored summaries", "error", err)
+		return err
+	}
+	failures, err := service.DbInterface.FindSummaryFailures(ctx, constants.SUMMARY_FAILURES, articleIDs)
+	if err != nil {
+		service.Logger.Error("Failed to fetch summary failures", "error", err)
+		return err
+	}
+
+	for _, article := range unchecked {
+		contentHash := newsArticle.SummaryContentHash(article.Title, article.Description)
+		if summary, ok := stored[article.ID]; ok && summary.ContentHash == contentHash {
+			continue
+		}
+		failure, failed := failures[article.ID]
+		if failed && failure.ContentHash != contentHash {
+			// The article changed since, it is retried at once
+			failed = false
+		}
+		if failed && time.Now().Before(failure.NextAttemptAt) {
+			pregen.result.Deferred++
+			continue
+		}
+
+		pregen.result.ToGenerate++
+		if pregen.options.DryRun {
+			continue
+		}
+		if pregen.done() {
+			return nil
+		}
+		if err := pregen.wait(ctx); err != nil {
+			return err
+		}
+
+		_, err := service.generateSummary(ctx, article, contentHash, model, promptVersion)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			attempts := 1
+			if failed {
+				attempts = failure.Attempts + 1
+			}
+			pregen.result.Failed++
+			service.Logger.Warn("Failed to pre-generate article summary", "article_id", article.ID, "attempts", attempts, "error", err)
+			now := time.Now()
+			err = service.DbInterface.SaveSummaryFailure(ctx, constants.SUMMARY_FAILURES, newsArticle.SummaryFailure{
+				ArticleID:     article.ID,
+				ContentHash:   contentHash,
+				Attempts:      attempts,
+				LastError:     err.Error(),
+				NextAttemptAt: now.Add(service.summaryRetryBackoff(attempts)),
+				UpdatedAt:     now,
+			})
+			if err != nil {
+				service.Logger.Warn("Failed to save summary failure", "article_id", article.ID, "error", err)
+			}
+			continue
+		}
+
+		pregen.result.Generated++
+		if _, ok := failures[article.ID]; ok {
+			if err := service.DbInterface.DeleteSummaryFailure(ctx, constants.SUMMARY_FAILURES, article.ID); err != nil {
+				service.Logger.Warn("Failed to delete summary failure", "article_id", article.ID, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// wait waits until the rate limit lets the next summary be generated.
+func (pregen *summaryPregen) wait(ctx context.Context) error {
+	if pregen.limiter == nil {
+		return nil
+	}
+	for {
+		taken, err := pregen.limiter.Take(ctx, SUMMARY_PREGEN_JOB, pregen.limit, time.Now())
+		if err != nil || taken.Allowed {
+			return err
+		}
+		select {
+		case <-time.After(taken.RetryAfter):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// summaryRetryBackoff is how long to wait before retrying a summary which failed 'attempts' times,
+// doubling SummaryRetryBackoff after every attempt.
+func (service *NewsService) summaryRetryBackoff(attempts int) time.Duration {
+	backoff := float64(service.Config.SummaryRetryBackoff) * math.Pow(2, float64(attempts-1))
+	return time.Duration(math.Min(backoff, float64(maxSummaryRetryBackoff)))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/reqctx"
+)
+
+type summaryModeKey struct{}
+
+// WithSummaryMode sets how ArticleSummaryHelper fills in the summaries of the request's listings,
+// one of the newsArticle.SUMMARY_MODE_*.
+func WithSummaryMode(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, summaryModeKey{}, mode)
+}
+
+// summaryMode is the request's summary mode, eager when none was set.
+func summaryMode(ctx context.Context) string {
+	if mode, ok := ctx.Value(summaryModeKey{}).(string); ok && mode != "" {
+		return mode
+	}
+	return newsArticle.SUMMARY_MODE_EAGER
+}
+
+// SummaryJob is an article whose summary is generated in the background, charged to the API key
+// of the request which listed it.
+type SummaryJob struct {
+	Article  newsArticle.NewsArticleDBResponse
+	APIKeyID string
+}
+
+// SummaryQueue queues the summaries of the lazy listings. Enqueue returns false when the job is
+// not queued, e.g. as the queue is full.
+type SummaryQueue interface {
+	Enqueue(job SummaryJob) bool
+}
+
+// GenerateQueuedSummary generates and stores the summary of a queued job, unless a summary of the
+// article's current content is already stored.
+func (service *NewsService) GenerateQueuedSummary(ctx context.Context, job SummaryJob) error {
+	ctx = reqctx.WithAPIKeyID(ctx, job.APIKeyID)
+	_, err := service.articleSummary(ctx, job.Article)
+	return err
+}
+
+// SummarizeArticleService returns the summary of the article, generating and storing it unless
+// a summary of its current content is stored. Without LLM calls (see WithoutLLM) only a stored
+// summary is returned, with the skipped status otherwise.
+// It returns ErrArticleNotFound when the article does not exist.
+func (service *NewsService) SummarizeArticleService(
+	ctx context.Context,
+	articleID string,
+) (*newsArticle.ArticleSummaryResult, error) {
+	service.Logger.Debug("'Service Layer': Summarizing article...")
+
+	articles, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, []string{articleID}, newsArticle.ArticleFilters{})
+	if err != nil {
+		service.Logger.Error("Failed to fetch article", "error", err)
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, ErrArticleNotFound
+	}
+
+	result, err := service.articleSummary(ctx, articles[0])
+	if err != nil {
+		service.Logger.Error("Failed to summarize article", "article_id", articleID, "error", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// articleSummary returns the stored summary of the article's current content, or generates it.
+func (service *NewsService) articleSummary(
+	ctx context.Context,
+	article newsArticle.NewsArticleDBResponse,
+) (*newsArticle.ArticleSummaryResult, error) {
+	model := service.LLMService.Model()
+	promptVersion := constants.ARTICLE_NEWS_SUMMARY_PROMPT_VERSION
+	result := &newsArticle.ArticleSummaryResult{
+		ArticleID:     article.ID,
+		Model:         model,
+		PromptVersion: promptVersion,
+	}
+
+	contentHash := newsArticle.SummaryContentHash(article.Title, article.Description)
+	stored, err := service.DbInterface.FindSummaries(ctx, constants.SUMMARIES, []string{article.ID}, model, promptVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stored summary: %w", err)
+	}
+	if summary, ok := stored[article.ID]; ok && summary.ContentHash == contentHash {
+		result.Summary = summary.Summary
+		result.SummaryStatus = newsArticle.SUMMARY_STATUS_CACHED
+		return result, nil
+	}
+	if llmDisabled(ctx) {
+		result.SummaryStatus = newsArticle.SUMMARY_STATUS_SKIPPED
+		return result, nil
+	}
+
+	summary, err := service.generateSummary(ctx, article, contentHash, model, promptVersion)
+	if err != nil {
+		return nil, err
+	}
+	result.Summary = summary
+	result.SummaryStatus = newsArticle.SUMMARY_STATUS_OK
+	return result, nil
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+// ErrTrendingSnapshotNotFound is returned for an 'as_of' request when no snapshot was built at or before that time.
+var ErrTrendingSnapshotNotFound = errors.New("no trending snapshot found")
+
+// staleSnapshotIntervals is how many refresh intervals old the latest snapshot may be before trending is computed live again.
+const staleSnapshotIntervals = 3
+
+// BuildTrendingSnapshots computes the trending lists of every window as of 'now' and stores them as snapshots:
+// one per category ("" for all categories) and geo cell ("" for global), each keeping the top TrendingSnapshotSize articles.
+// It returns the number of snapshots stored.
+func (service *NewsService) BuildTrendingSnapshots(ctx context.Context, now time.Time) (int, error) {
+	service.Logger.Debug("'Service Layer': Building trending snapshots...")
+	now = now.UTC().Truncate(time.Second)
+
+	var snapshots []newsArticle.TrendingSnapshot
+	for window := range newsArticle.TRENDING_WINDOWS {
+		scores, err := service.DbInterface.AggregateTrendingScoresByCell(ctx, constants.USER_EVENT, service.trendingScoring(window, now), service.trendingCellSizeDeg())
+		if err != nil {
+			service.Logger.Error("Failed to aggregate trending scores", "window", window, "error", err)
+			return 0, err
+		}
+
+		categories, err := service.articleCategories(ctx, scores)
+		if err != nil {
+			service.Logger.Error("Failed to fetch categories of trending articles", "window", window, "error", err)
+			return 0, err
+		}
+
+		// scores[category][cell][articleID]
+		grouped := map[string]map[string]map[string]float64{}
+		add := func(category string, cell string, articleID string, score float64) {
+			if grouped[category] == nil {
+				grouped[category] = map[string]map[string]float64{}
+			}
+			if grouped[category][cell] == nil {
+				grouped[category][cell] = map[string]float64{}
+			}
+			grouped[category][cell][articleID] += score
+		}
+
+		for _, score := range scores {
+			cells := []string{""}
+			if score.Key.X != nil && score.Key.Y != nil {
+				cells = append(cells, newsArticle.TrendingCellKey(*score.Key.X, *score.Key.Y))
+			}
+			for _, cell := range cells {
+				add("", cell, score.Key.ArticleID, score.Score)
+				for _, category := range categories[score.Key.ArticleID] {
+					add(category, cell, score.Key.ArticleID, score.Score)
+				}
+			}
+		}
+
+		for category, cells := range grouped {
+			for cell, articleScores := range cells {
+				snapshots = append(snapshots, newsArticle.TrendingSnapshot{
+					Window:   window,
+					Category: category,
+					Cell:     cell,
+					AsOf:     now,
+					Entries:  topSnapshotEntries(articleScores, service.Config.TrendingSnapshotSize),
+				})
+			}
+		}
+	}
+
+	if err := service.DbInterface.InsertTrendingSnapshots(ctx, constants.TRENDING_SNAPSHOTS, snapshots); err != nil {
+		service.Logger.Error("Failed to store trending snapshots", "error", err)
+		return 0, err
+	}
+
+	service.Logger.Info(fmt.Sprintf("Stored %d trending snapshots as of %s", len(snapshots), now.Format(time.RFC3339)))
+	return len(snapshots), nil
+}
+
+// articleCategories returns the lowercased, de-duplicated categories of every scored article.
+func (service *NewsService) articleCategories(
+	ctx context.Context,
+	scores []newsArticle.TrendingCellScore,
+) (map[string][]string, error) {
+	seen := map[string]bool{}
+	articleIDs := []string{}
+	for _, score := range scores {
+		if !seen[score.Key.ArticleID] {
+			seen[score.Key.ArticleID] = true
+			articleIDs = append(articleIDs, score.Key.ArticleID)
+		}
+	}
+
+	articles, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, newsArticle.ArticleFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string][]string, len(articles))
+	for _, article := range articles {
+		unique := map[string]bool{}
+		for _, category := range article.Category {
+			category = strings.ToLower(strings.TrimSpace(category))
+			if category != "" && !unique[category] {
+				unique[category] = true
+				categories[article.ID] = append(categories[article.ID], category)
+			}
+		}
+	}
+	return categories, nil
+}
+
+// topSnapshotEntries orders the articles by score, ties by article ID, and keeps the first 'size'.
+func topSnapshotEntries(articleScores map[string]float64, size int) []newsArticle.TrendingSnapshotEntry {
+	entries := make([]newsArticle.TrendingSnapshotEntry, 0, len(articleScores))
+	for articleID, score := range articleScores {
+		entries = append(entries, newsArticle.TrendingSnapshotEntry{ArticleID: articleID, Score: score})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].ArticleID < entries[j].ArticleID
+	})
+	if size > 0 && len(entries) > size {
+		entries = entries[:size]
+	}
+	return entries
+}
+
+func (service *NewsService) trendingCellSizeDeg() float64 {
+	if service.Config.TrendingSnapshotCellDeg <= 0 {
+		return 1
+	}
+	return service.Config.TrendingSnapshotCellDeg
+}
+
+// trendingFromSnapshot serves trending from the latest snapshots built at or before 'asOf' (now when nil).
+// Snapshots only keep event locations, so a location with the 'articles' or 'both' scope is never served from them.
+// A location is served from every cell its radius reaches, and computed live when the radius is smaller than a cell
+// or reaches more than maxTrendingSnapshotCells cells, as the cells would not approximate it.
+// It returns nil articles when there is no usable snapshot and trending should be computed live instead.
+func (service *NewsService) trendingFromSnapshot(
+	ctx context.Context,
+	articleLimit int,
+	window string,
+	location *newsArticle.TrendingLocation,
+	filters newsArticle.ArticleFilters,
+	asOf *time.Time,
+) ([]newsArticle.NewsArticleDBResponse, *newsArticle.TrendingSource, error) {
+	now := time.Now()
+
+	cells := []string{""}
+	if location != nil {
+		cellSizeDeg := service.trendingCellSizeDeg()
+		cells = trendingCellsWithin(location.Latitude, location.Longitude, location.RadiusKm, cellSizeDeg)
+		// A past snapshot has no live alternative, so it is served from the cells anyway
+		if asOf == nil && (location.RadiusKm < cellSizeDeg*kmPerDegree || len(cells) > maxTrendingSnapshotCells) {
+			return nil, nil, nil
+		}
+	}
+	// Like the live filter, a category matches every category containing it
+	category := strings.ToLower(strings.TrimSpace(filters.Category))
+
+	lookupTime := now
+	if asOf != nil {
+		lookupTime = *asOf
+	}
+	snapshots, builtAt, err := service.DbInterface.FindTrendingSnapshots(ctx, constants.TRENDING_SNAPSHOTS, window, category, cells, lookupTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	if builtAt == nil {
+		if asOf != nil {
+			return nil, nil, ErrTrendingSnapshotNotFound
+		}
+		return nil, nil, nil
+	}
+
+	age := now.Sub(*builtAt)
+	if asOf == nil && age > staleSnapshotIntervals*service.Config.TrendingSnapshotInterval {
+		service.Logger.Warn("Latest trending snapshot is stale, computing trending live", "window", window, "as_of", *builtAt)
+		return nil, nil, nil
+	}
+
+	entries, truncated := mergeSnapshotEntries(snapshots, service.Config.TrendingSnapshotSize)
+	articleIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		articleIDs[i] = entry.ArticleID
+	}
+	found, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]newsArticle.NewsArticleDBResponse, len(found))
+	for _, article := range found {
+		byID[article.ID] = article
+	}
+
+	// Keep the snapshot order, skipping articles which were deleted or do not match the filters
+	articles := []newsArticle.NewsArticleDBResponse{}
+	for _, entry := range entries {
+		// A limit which is not positive is no limit, like in the data layer
+		if articleLimit > 0 && len(articles) >= articleLimit {
+			break
+		}
+		article, ok := byID[entry.ArticleID]
+		if !ok {
+			continue
+		}
+		score := entry.Score
+		article.TrendScore = &score
+		article.TrendRank = len(articles) + 1
+		articles = append(articles, article)
+	}
+
+	// Without the articles the user has seen, a full snapshot can run short; the live computation backfills it
+	if asOf == nil && len(filters.ExcludeArticleIDs) > 0 && (articleLimit <= 0 || len(articles) < articleLimit) && truncated {
+		return nil, nil, nil
+	}
+
+	ageSeconds := age.Seconds()
+	source := &newsArticle.TrendingSource{
+		Source:     newsArticle.TRENDING_SOURCE_SNAPSHOT,
+		AsOf:       builtAt,
+		AgeSeconds: &ageSeconds,
+	}
+	if location != nil {
+		source.Cells = cells
+	}
+	return articles, source, nil
+}
+
+// mergeSnapshotEntries merges the snapshots of one build into a single trending list. An article has the same score
+// in every category snapshot of a cell, so its score is the one of its cell, summed over the cells. It also tells
+// whether a snapshot was full, so articles past its end may be missing.
+func mergeSnapshotEntries(snapshots []newsArticle.TrendingSnapshot, size int) ([]newsArticle.TrendingSnapshotEntry, bool) {
+	cellScores := map[string]map[string]float64{}
+	truncated := false
+	for _, snapshot := range snapshots {
+		if cellScores[snapshot.Cell] == nil {
+			cellScores[snapshot.Cell] = map[string]float64{}
+		}
+		for _, entry := range snapshot.Entries {
+			cellScores[snapshot.Cell][entry.ArticleID] = max(cellScores[snapshot.Cell][entry.ArticleID], entry.Score)
+		}
+		if size > 0 && len(snapshot.Entries) >= size {
+			truncated = true
+		}
+	}
+
+	articleScores := map[string]float64{}
+	for _, scores := range cellScores {
+		for articleID, score := range scores {
+			articleScores[articleID] += score
+		}
+	}
+	return topSnapshotEntries(articleScores, 0), truncated
+}
+
+const (
+	// kmPerDegree is the length of a degree of latitude
+	kmPerDegree = utils.EarthRadiusKm * math.Pi / 180
+	// maxTrendingSnapshotCells is the most snapshot cells a location is served from before it is computed live
+	maxTrendingSnapshotCells = 64
+)
+
+// trendingCellsWithin returns the keys of the 'cellSizeDeg' geo cells which are at least partly within 'radiusKm' of the coordinate.
+func trendingCellsWithin(latitude float64, longitude float64, radiusKm float64, cellSizeDeg float64) []string {
+	columns := int(math.Ceil(360 / cellSizeDeg))
+	rows := int(math.Ceil(180 / cellSizeDeg))
+
+	radiusDeg := radiusKm / kmPerDegree
+	minY := max(int(math.Floor((latitude-radiusDeg+90)/cellSizeDeg)), 0)
+	maxY := min(int(math.Floor((latitude+radiusDeg+90)/cellSizeDeg)), rows-1)
+
+	// Near the poles the radius can span every longitude
+	minX, maxX := 0, columns-1
+	if cosLatitude := math.Cos(latitude * math.Pi / 180); cosLatitude > 0 && radiusDeg/cosLatitude < 180 {
+		minX = int(math.Floor((longitude - radiusDeg/cosLatitude + 180) / cellSizeDeg))
+		maxX = int(math.Floor((longitude + radiusDeg/cosLatitude + 180) / cellSizeDeg))
+	}
+
+	seen := map[string]bool{}
+	cells := []string{}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			// The nearest point of the cell, whose x may run past the antimeridian before it wraps around
+			cellLatitude := math.Max(float64(y)*cellSizeDeg-90, math.Min(latitude, float64(y+1)*cellSizeDeg-90))
+			cellLongitude := math.Max(float64(x)*cellSizeDeg-180, math.Min(longitude, float64(x+1)*cellSizeDeg-180))
+			if utils.HaversineKm(latitude, longitude, cellLatitude, cellLongitude) > radiusKm {
+				continue
+			}
+
+			key := newsArticle.TrendingCellKey(((x%columns)+columns)%columns, y)
+			if !seen[key] {
+				seen[key] = true
+				cells = append(cells, key)
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
+)
+
+// userProfile returns the interest profile of the user from the cache, the database,
+// or built from their event history. It returns nil for a user without events.
+func (service *NewsService) userProfile(ctx context.Context, userID string) (*newsArticle.UserProfile, error) {
+	profile, found, err := service.loadUserProfile(ctx, userID)
+	if err != nil || found {
+		return profile, err
+	}
+
+	unlock := service.profileLocks.Lock(userID)
+	defer unlock()
+	// It may have been built while waiting
+	profile, found, err = service.loadUserProfile(ctx, userID)
+	if err != nil || found {
+		return profile, err
+	}
+	profile, err = service.buildUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	service.profileCache.Set(userID, profile)
+	return profile, nil
+}
+
+// loadUserProfile reads the profile from the cache or the database. 'found' is false when neither knows the user;
+// a cached nil profile is a user known to have no events.
+func (service *NewsService) loadUserProfile(ctx context.Context, userID string) (*newsArticle.UserProfile, bool, error) {
+	if profile, ok := service.profileCache.Get(userID); ok {
+		return profile, true, nil
+	}
+
+	profile, err := service.DbInterface.FindUserProfile(ctx, constants.USER_PROFILES, userID)
+	if err != nil || profile == nil {
+		return nil, false, err
+	}
+	service.profileCache.Set(userID, profile)
+	return profile, true, nil
+}
+
+// buildUserProfile builds and stores the profile of the user from their events of the last UserProfileHistory.
+func (service *NewsService) buildUserProfile(ctx context.Context, userID string) (*newsArticle.UserProfile, error) {
+	service.Logger.Debug("'Service Layer': Building user profile from event history...")
+
+	eventTypes := make([]string, 0, len(service.Config.UserProfileEventWeights))
+	for eventType := range service.Config.UserProfileEventWeights {
+		eventTypes = append(eventTypes, eventType)
+	}
+	events, err := service.DbInterface.FindUserEvents(ctx, constants.USER_EVENT, userID, eventTypes, time.Now().Add(-service.Config.UserProfileHistory), maxProfileEvents)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	profile, err := service.applyEventsToProfile(ctx, &newsArticle.UserProfile{UserID: userID}, events)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.DbInterface.SaveUserProfile(ctx, constants.USER_PROFILES, *profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateUserProfiles folds newly written events into the profiles of their users.
+// Users without a profile yet get one built from their history, which already contains the events.
+func (service *NewsService) UpdateUserProfiles(ctx context.Context, events []newsArticle.UserEvent) error {
+	service.Logger.Debug("'Service Layer': Updating user profiles...")
+
+	byUser := map[string][]newsArticle.UserEvent{}
+	for _, event := range events {
+		if event.UserID != "" && service.Config.UserProfileEventWeights[event.EventType] > 0 {
+			byUser[event.UserID] = append(byUser[event.UserID], event)
+		}
+	}
+
+	for userID, userEvents := range byUser {
+		if err := service.updateUserProfile(ctx, userID, userEvents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateUserProfile folds the events into the user's profile while holding the user's profile lock.
+func (service *NewsService) updateUserProfile(ctx context.Context, userID string, events []newsArticle.UserEvent) error {
+	unlock := service.profileLocks.Lock(userID)
+	defer unlock()
+
+	profile, found, err := service.loadUserProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !found || profile == nil {
+		profile, err = service.buildUserProfile(ctx, userID)
+		if err != nil {
+			return err
+		}
+		service.profileCache.Set(userID, profile)
+		return nil
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	updated, err := service.applyEventsToProfile(ctx, profile, events)
+	if err != nil {
+		return err
+	}
+	if err := service.DbInterface.SaveUserProfile(ctx, constants.USER_PROFILES, *updated); err != nil {
+		return err
+	}
+	service.profileCache.Set(userID, updated)
+	return nil
+}
+
+// applyEventsToProfile returns a copy of the profile with the events, oldest first, added to it.
+// Before each event the existing affinities decay for the time passed since the previous one.
+func (service *NewsService) applyEventsToProfile(
+	ctx context.Context,
+	profile *newsArticle.UserProfile,
+	events []newsArticle.UserEvent,
+) (*newsArticle.UserProfile, error) {
+	articleIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		articleIDs = append(articleIDs, event.ArticleID)
+	}
+	articles, err := service.DbInterface.FindArticlesByIDs(ctx, constants.NEWS, articleIDs, newsArticle.ArticleFilters{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]newsArticle.NewsArticleDBResponse, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	updated := *profile
+	categories := affinityMap(profile.Categories)
+	sources := affinityMap(profile.Sources)
+	keywords := affinityMap(profile.Keywords)
+
+	for _, event := range events {
+		article, ok := byID[event.ArticleID]
+		if !ok {
+			continue
+		}
+
+		if !updated.UpdatedAt.IsZero() && event.Timestamp.After(updated.UpdatedAt) && service.Config.UserProfileHalfLife > 0 {
+			decay := math.Pow(0.5, event.Timestamp.Sub(updated.UpdatedAt).Hours()/service.Config.UserProfileHalfLife.Hours())
+			for _, affinities := range []map[string]float64{categories, sources, keywords} {
+				for key := range affinities {
+					affinities[key] *= decay
+				}
+			}
+			updated.LocationWeight *= decay
+		}
+		if event.Timestamp.After(updated.UpdatedAt) {
+			updated.UpdatedAt = event.Timestamp
+		}
+
+		weight := service.Config.UserProfileEventWeights[event.EventType]
+		for _, category := range article.Category {
+			if category = strings.ToLower(strings.TrimSpace(category)); category != "" {
+				categories[category] += weight
+			}
+		}
+		if source := strings.ToLower(strings.TrimSpace(article.SourceName)); source != "" {
+			sources[source] += weight
+		}
+		for _, keyword := range utils.ExtractKeywords(article.Title) {
+			keywords[keyword] += weight
+		}
+
+		// The typical location is where the user reads from, or else where the articles they read are located
+		latitude, longitude := article.Latitude, article.Longitude
+		if event.Location != nil {
+			latitude, longitude = event.Latitude, event.Longitude
+		}
+		if updated.Latitude == nil || updated.Longitude == nil || updated.LocationWeight <= 0 {
+			updated.Latitude, updated.Longitude, updated.LocationWeight = &latitude, &longitude, weight
+		} else {
+			total := updated.LocationWeight + weight
+			meanLatitude := (*updated.Latitude*updated.LocationWeight + latitude*weight) / total
+			meanLongitude := (*updated.Longitude*updated.LocationWeight + longitude*weight) / total
+			updated.Latitude, updated.Longitude, updated.LocationWeight = &meanLatitude, &meanLongitude, total
+		}
+		updated.EventCount++
+	}
+
+	updated.Categories = topAffinities(categories, maxProfileAffinities)
+	updated.Sources = topAffinities(sources, maxProfileAffinities)
+	updated.Keywords = topAffinities(keywords, maxProfileAffinities)
+	return &updated, nil
+}
+
+func affinityMap(affinities []newsArticle.Affinity) map[string]float64 {
+	weights := make(map[string]float64, len(affinities))
+	for _, affinity := range affinities {
+		weights[affinity.Key] = affinity.Weight
+	}
+	return weights
+}
+
+// topAffinities returns the 'size' strongest affinities, strongest first.
+func topAffinities(weights map[string]float64, size int) []newsArticle.Affinity {
+	affinities := make([]newsArticle.Affinity, 0, len(weights))
+	for key, weight := range weights {
+		affinities = append(affinities, newsArticle.Affinity{Key: key, Weight: weight})
+	}
+	sort.Slice(affinities, func(i, j int) bool {
+		if affinities[i].Weight != affinities[j].Weight {
+			return affinities[i].Weight > affinities[j].Weight
+		}
+		return affinities[i].Key < affinities[j].Key
+	})
+	if len(affinities) > size {
+		affinities = affinities[:size]
+	}
+	return affinities
+}
@@ -0,0 +1,267 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shivam-cse/contextual-news-api/internal/dbInterface"
+	"github.com/shivam-cse/contextual-news-api/internal/models/newsArticle"
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/constants"
+	"github.com/shivam-cse/contextual-news-api/pkg/metrics"
+)
+
+const (
+	// EVENT_QUEUE_FULL_DROP accepts what fits in the queue and drops the rest of the batch
+	EVENT_QUEUE_FULL_DROP = "drop"
+	// EVENT_QUEUE_FULL_REJECT queues nothing and lets the request fail with 503
+	EVENT_QUEUE_FULL_REJECT = "reject"
+)
+
+// A failed flush is retried this many times, waiting flushRetryBackoff longer each time, before the batch is given up.
+const (
+	flushAttempts     = 3
+	flushRetryBackoff = 200 * time.Millisecond
+)
+
+var (
+	eventQueueDepth    = metrics.NewGauge("news_event_queue_depth", "User events waiting to be written.")
+	eventQueueCapacity = metrics.NewGauge("news_event_queue_capacity", "Maximum number of queued user events.")
+	eventsEnqueued     = metrics.NewCounter("news_events_enqueued_total", "User events accepted into the queue.")
+	eventsDropped      = metrics.NewCounter("news_events_dropped_total", "User events dropped because the queue was full.")
+	eventsRejected     = metrics.NewCounter("news_events_rejected_total", "User events refused with 503 because the queue was full.")
+	eventsWritten      = metrics.NewCounter("news_events_written_total", "User events written to MongoDB.")
+	eventsDuplicate    = metrics.NewCounter("news_events_duplicate_total", "Queued user events skipped by the unique client_event_id index.")
+	eventsFailed       = metrics.NewCounter("news_events_write_failed_total", "User events lost after every flush attempt failed.")
+	eventFlushDuration = metrics.NewHistogram("news_event_flush_duration_seconds", "Time taken by one InsertMany flush.",
+		[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5})
+)
+
+// EventWriter buffers user events in a bounded in-memory queue and writes them with InsertMany,
+// whenever BatchSize events are queued or every FlushInterval, whichever comes first.
+// It implements services.EventPublisher.
+type EventWriter struct {
+	DbInterface   *dbInterface.NewsDbInterface
+	Logger        *slog.Logger
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueFullMode string
+	// OnWritten, when set, is called with the events of every successful flush (e.g. to update user profiles)
+	OnWritten func(ctx context.Context, events []newsArticle.UserEvent) error
+
+	mu      sync.Mutex
+	queue   []newsArticle.UserEvent
+	pending map[string]bool // client_event_id of the queued events
+	closed  bool
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewEventWriter(
+	dbInterface *dbInterface.NewsDbInterface,
+	logger *slog.Logger,
+	queueSize int,
+	batchSize int,
+	flushInterval time.Duration,
+	queueFullMode string,
+) *EventWriter {
+	if queueSize <= 0 {
+		queueSize = 10000
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	eventQueueCapacity.Set(float64(queueSize))
+	return &EventWriter{
+		DbInterface:   dbInterface,
+		Logger:        logger,
+		QueueSize:     queueSize,
+		BatchSize:     max(batchSize, 1),
+		FlushInterval: flushInterval,
+		QueueFullMode: queueFullMode,
+		pending:       map[string]bool{},
+		flush:         make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Publish queues the events and returns the outcome of each one: accepted, duplicate when an event
+// with the same client_event_id is still queued, or dropped when the queue is full in 'drop' mode.
+// In 'reject' mode nothing is queued unless every event fits, and services.ErrEventQueueFull is returned.
+func (writer *EventWriter) Publish(events []newsArticle.UserEvent) ([]string, error) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	if writer.closed {
+		return nil, services.ErrEventQueueFull
+	}
+
+	statuses := make([]string, len(events))
+	fresh := 0
+	for i, event := range events {
+		if writer.pending[event.ClientEventID] {
+			statuses[i] = newsArticle.EVENT_DUPLICATE
+			continue
+		}
+		fresh++
+	}
+
+	free := writer.QueueSize - len(writer.queue)
+	if fresh > free && writer.QueueFullMode != EVENT_QUEUE_FULL_DROP {
+		eventsRejected.Add(float64(fresh))
+		return nil, services.ErrEventQueueFull
+	}
+
+	for i, event := range events {
+		if statuses[i] != "" {
+			continue
+		}
+		if len(writer.queue) >= writer.QueueSize {
+			statuses[i] = newsArticle.EVENT_DROPPED
+			eventsDropped.Inc()
+			continue
+		}
+		writer.queue = append(writer.queue, event)
+		writer.pending[event.ClientEventID] = true
+		statuses[i] = newsArticle.EVENT_ACCEPTED
+		eventsEnqueued.Inc()
+	}
+	eventQueueDepth.Set(float64(len(writer.queue)))
+
+	if len(writer.queue) >= writer.BatchSize {
+		select {
+		case writer.flush <- struct{}{}:
+		default:
+		}
+	}
+	return statuses, nil
+}
+
+// Start runs the flush loop in the background until Close.
+func (writer *EventWriter) Start() {
+	go writer.run()
+}
+
+func (writer *EventWriter) run() {
+	defer close(writer.done)
+	writer.Logger.Info("Event writer started", "queue_size", writer.QueueSize, "batch_size", writer.BatchSize, "flush_interval", writer.FlushInterval)
+
+	ticker := time.NewTicker(writer.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-writer.stop:
+			// Drain everything that was queued before Close
+			for writer.flushBatch() > 0 {
+			}
+			writer.Logger.Info("Event writer stopped")
+			return
+		case <-writer.flush:
+			for writer.queueLen() >= writer.BatchSize {
+				writer.flushBatch()
+			}
+		case <-ticker.C:
+			writer.flushBatch()
+		}
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are written or ctx is done.
+func (writer *EventWriter) Close(ctx context.Context) error {
+	writer.mu.Lock()
+	if !writer.closed {
+		writer.closed = true
+		close(writer.stop)
+	}
+	writer.mu.Unlock()
+
+	select {
+	case <-writer.done:
+		return nil
+	case <-ctx.Done():
+		writer.Logger.Error("Event writer did not flush in time", "queued", writer.queueLen())
+		return ctx.Err()
+	}
+}
+
+func (writer *EventWriter) queueLen() int {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	return len(writer.queue)
+}
+
+// flushBatch writes up to BatchSize queued events and returns how many were taken from the queue.
+func (writer *EventWriter) flushBatch() int {
+	writer.mu.Lock()
+	n := min(len(writer.queue), writer.BatchSize)
+	batch := append([]newsArticle.UserEvent(nil), writer.queue[:n]...)
+	writer.mu.Unlock()
+	if n == 0 {
+		return 0
+	}
+
+	var err error
+	for attempt := 1; attempt <= flushAttempts; attempt++ {
+		var duplicates map[int]bool
+		duplicates, err = writer.insert(batch)
+		if err == nil {
+			eventsWritten.Add(float64(len(batch) - len(duplicates)))
+			eventsDuplicate.Add(float64(len(duplicates)))
+			writer.notifyWritten(batch, duplicates)
+			break
+		}
+		writer.Logger.Warn("Failed to flush user events", "attempt", attempt, "events", len(batch), "error", err)
+		if attempt < flushAttempts {
+			time.Sleep(time.Duration(attempt) * flushRetryBackoff)
+		}
+	}
+	if err != nil {
+		writer.Logger.Error("Giving up on user events after failed flushes", "events", len(batch), "error", err)
+		eventsFailed.Add(float64(len(batch)))
+	}
+
+	// The batch leaves the queue only now, so that retried client events stay de-duplicated meanwhile
+	writer.mu.Lock()
+	writer.queue = writer.queue[n:]
+	for _, event := range batch {
+		delete(writer.pending, event.ClientEventID)
+	}
+	eventQueueDepth.Set(float64(len(writer.queue)))
+	writer.mu.Unlock()
+	return n
+}
+
+func (writer *EventWriter) notifyWritten(batch []newsArticle.UserEvent, duplicates map[int]bool) {
+	if writer.OnWritten == nil {
+		return
+	}
+	written := make([]newsArticle.UserEvent, 0, len(batch))
+	for i, event := range batch {
+		if !duplicates[i] {
+			written = append(written, event)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := writer.OnWritten(ctx, written); err != nil {
+		writer.Logger.Error("Failed to process written user events", "events", len(written), "error", err)
+	}
+}
+
+func (writer *EventWriter) insert(batch []newsArticle.UserEvent) (map[int]bool, error) {
+	// Flushes run detached from any request, and also after the server context is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	duplicates, err := writer.DbInterface.InsertUserEvents(ctx, constants.USER_EVENT, batch)
+	eventFlushDuration.Observe(time.Since(start).Seconds())
+	return duplicates, err
+}
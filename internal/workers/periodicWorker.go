@@ -0,0 +1,59 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PeriodicWorker runs a background job, such as building the trending snapshots, on a fixed interval.
+type PeriodicWorker struct {
+	Name     string
+	Logger   *slog.Logger
+	Interval time.Duration
+	Job      func(ctx context.Context) error
+}
+
+func NewPeriodicWorker(
+	name string,
+	logger *slog.Logger,
+	interval time.Duration,
+	job func(ctx context.Context) error,
+) *PeriodicWorker {
+	return &PeriodicWorker{
+		Name:     name,
+		Logger:   logger,
+		Interval: interval,
+		Job:      job,
+	}
+}
+
+// Run runs the job once straight away and then every interval, until ctx is cancelled.
+// A failed run is logged and retried on the next tick.
+func (worker *PeriodicWorker) Run(ctx context.Context) {
+	worker.Logger.Info("Worker started", "worker", worker.Name, "interval", worker.Interval)
+
+	ticker := time.NewTicker(worker.Interval)
+	defer ticker.Stop()
+
+	for {
+		worker.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			worker.Logger.Info("Worker stopped", "worker", worker.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (worker *PeriodicWorker) runOnce(ctx context.Context) {
+	// A run must not overlap with the next one
+	runCtx, cancel := context.WithTimeout(ctx, worker.Interval)
+	defer cancel()
+
+	if err := worker.Job(runCtx); err != nil {
+		worker.Logger.Error("Worker run failed", "worker", worker.Name, "error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/shivam-cse/contextual-news-api/internal/services"
+	"github.com/shivam-cse/contextual-news-api/pkg/metrics"
+)
+
+var (
+	summaryQueueDepth = metrics.NewGauge("news_summary_queue_depth", "Article summaries waiting to be generated in the background.")
+	summariesQueued   = metrics.NewCounter("news_summaries_queued_total", "Article summaries queued for background generation.")
+	summariesDropped  = metrics.NewCounter("news_summaries_dropped_total", "Article summaries not queued because the queue was full.")
+	summariesFailed   = metrics.NewCounter("news_summaries_failed_total", "Background article summaries which failed to generate.")
+)
+
+// SummaryGenerator generates queued article summaries with a fixed number of workers, from a bounded
+// in-memory queue. An article already queued is not queued again. It implements services.SummaryQueue.
+type SummaryGenerator struct {
+	Generate  func(ctx context.Context, job services.SummaryJob) error
+	Logger    *slog.Logger
+	QueueSize int
+	Workers   int
+
+	mu      sync.Mutex
+	pending map[string]bool // IDs of the queued and in-progress articles
+	closed  bool
+	jobs    chan services.SummaryJob
+	cancel  context.CancelFunc
+	done    sync.WaitGroup
+}
+
+func NewSummaryGenerator(
+	generate func(ctx context.Context, job services.SummaryJob) error,
+	logger *slog.Logger,
+	queueSize int,
+	workers int,
+) *SummaryGenerator {
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+	return &SummaryGenerator{
+		Generate:  generate,
+		Logger:    logger,
+		QueueSize: queueSize,
+		Workers:   max(workers, 1),
+		pending:   map[string]bool{},
+		jobs:      make(chan services.SummaryJob, queueSize),
+	}
+}
+
+// Enqueue queues the job unless its article is already queued, and returns false when the queue is
+// full or closed.
+func (generator *SummaryGenerator) Enqueue(job services.SummaryJob) bool {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+
+	if generator.closed {
+		return false
+	}
+	if generator.pending[job.Article.ID] {
+		return true
+	}
+	select {
+	case generator.jobs <- job:
+		generator.pending[job.Article.ID] = true
+		summariesQueued.Inc()
+		summaryQueueDepth.Set(float64(len(generator.jobs)))
+		return true
+	default:
+		summariesDropped.Inc()
+		return false
+	}
+}
+
+// Start runs the workers in the background until Close.
+func (generator *SummaryGenerator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	generator.cancel = cancel
+	generator.Logger.Info("Summary generator started", "queue_size", generator.QueueSize, "workers", generator.Workers)
+
+	for range generator.Workers {
+		generator.done.Add(1)
+		go generator.run(ctx)
+	}
+}
+
+func (generator *SummaryGenerator) run(ctx context.Context) {
+	defer generator.done.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-generator.jobs:
+			summaryQueueDepth.Set(float64(len(generator.jobs)))
+			if err := generator.Generate(ctx, job); err != nil && ctx.Err() == nil {
+				summariesFailed.Inc()
+				generator.Logger.Warn("Failed to generate queued summary", "article_id", job.Article.ID, "error", err)
+			}
+			generator.mu.Lock()
+			delete(generator.pending, job.Article.ID)
+			generator.mu.Unlock()
+		}
+	}
+}
+
+// Close stops accepting jobs, drops the queued ones, which are queued again by later listings,
+// and waits until the workers return or ctx is done.
+func (generator *SummaryGenerator) Close(ctx context.Context) error {
+	generator.mu.Lock()
+	generator.closed = true
+	generator.mu.Unlock()
+	if generator.cancel != nil {
+		generator.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		generator.done.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		generator.Logger.Info("Summary generator stopped", "dropped", len(generator.jobs))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
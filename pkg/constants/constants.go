@@ -1,10 +1,25 @@
 package constants
 
 const (
-	NEWS       = "news"
-	USER_EVENT = "user_event"
-	USERS      = "users"
-	SUCCESS    = "success"
-	FAILED     = "failed"
-	DETAILS    = "details"
+	NEWS               = "news"
+	USER_EVENT         = "user_event"
+	USERS              = "users"
+	TRENDING_SNAPSHOTS = "trending_snapshots"
+	USER_PROFILES      = "user_profiles"
+	ARTICLE_NEIGHBORS  = "article_neighbors"
+	BOOKMARKS          = "bookmarks"
+	READ_HISTORY       = "read_history"
+	API_KEYS           = "api_keys"
+	RATE_LIMITS        = "rate_limits"
+	LLM_USAGE          = "llm_usage"
+	SUMMARIES          = "summaries"
+	SUMMARY_FAILURES   = "summary_failures"
+	JOB_CHECKPOINTS    = "job_checkpoints"
+	SUCCESS            = "success"
+	FAILED             = "failed"
+	DETAILS            = "details"
 )
+
+// PUBLICATION_DATE_LAYOUT is how 'publication_date' is stored on the articles, e.g. "2025-03-26T04:46:55".
+// Being zero padded, the stored strings sort and compare in date order.
+const PUBLICATION_DATE_LAYOUT = "2006-01-02T15:04:05"
@@ -1,5 +1,9 @@
 package constants
 
+// ARTICLE_NEWS_SUMMARY_PROMPT_VERSION is stored with every generated summary.
+// Bump it when changing the summary prompts, so that the stored summaries are generated again.
+const ARTICLE_NEWS_SUMMARY_PROMPT_VERSION = "1"
+
 const ARTICLE_NEWS_SUMMARY_SYSTEM_PROMPT = `
 You are a news summarization assistant so Summarize the news article
 `
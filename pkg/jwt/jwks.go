@@ -0,0 +1,205 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often a token with an unknown 'kid' can make a key set reload its source.
+const minRefreshInterval = time.Minute
+
+// maxJWKSSize bounds the JWKS documents read from a URL
+const maxJWKSSize = 1 << 20
+
+// KeySet holds the RSA public keys of a JWKS document by their 'kid'. The source is either a file path
+// or an http(s) URL, which is reloaded every 'refresh' and when a token names a key it does not have yet,
+// so that keys rotated by the identity provider are picked up. A failed reload keeps the previous keys.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+
+	mu       sync.RWMutex
+	keys     map[string]*rsa.PublicKey
+	loadedAt time.Time
+	// loading is held during a reload, so that concurrent requests do not all reload
+	loading sync.Mutex
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// NewKeySet loads the key set from 'source', failing when it cannot be read or has no usable key.
+func NewKeySet(ctx context.Context, source string, refresh time.Duration) (*KeySet, error) {
+	keySet := &KeySet{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := keySet.load(ctx); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// Key returns the key with the ID, or the only key of the set when the token has no 'kid'.
+func (keySet *KeySet) Key(keyID string) (*rsa.PublicKey, error) {
+	if keySet.isRemote() {
+		keySet.mu.RLock()
+		age := time.Since(keySet.loadedAt)
+		_, known := keySet.keys[keyID]
+		keySet.mu.RUnlock()
+		if (keySet.refresh > 0 && age > keySet.refresh) || (!known && keyID != "" && age > minRefreshInterval) {
+			// Keeps the keys it has on failure, the token is then checked against those
+			if keySet.loading.TryLock() {
+				_ = keySet.load(context.Background())
+				keySet.loading.Unlock()
+			}
+		}
+	}
+
+	keySet.mu.RLock()
+	defer keySet.mu.RUnlock()
+	if keyID == "" {
+		if len(keySet.keys) == 1 {
+			for _, key := range keySet.keys {
+				return key, nil
+			}
+		}
+		return nil, errors.New("the token has no key ID")
+	}
+	key, ok := keySet.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", keyID)
+	}
+	return key, nil
+}
+
+func (keySet *KeySet) isRemote() bool {
+	return strings.HasPrefix(keySet.source, "http://") || strings.HasPrefix(keySet.source, "https://")
+}
+
+func (keySet *KeySet) load(ctx context.Context) error {
+	var data []byte
+	var err error
+	if keySet.isRemote() {
+		data, err = keySet.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(keySet.source)
+	}
+
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+	// Failed attempts count too, so that an unreachable source is not retried on every request
+	keySet.loadedAt = time.Now()
+	if err != nil {
+		return fmt.Errorf("jwt: failed to load JWKS from %s: %w", keySet.source, err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("jwt: failed to parse JWKS from %s: %w", keySet.source, err)
+	}
+	keySet.keys = keys
+	return nil
+}
+
+func (keySet *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keySet.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := keySet.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// ParseJWKS returns the RSA signing keys of a JWKS document by their 'kid'. Other keys are skipped.
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, webKey := range document.Keys {
+		if webKey.KeyType != "RSA" || (webKey.Use != "" && webKey.Use != "sig") || (webKey.Algorithm != "" && webKey.Algorithm != RS256) {
+			continue
+		}
+		modulus, err := base64.RawURLEncoding.DecodeString(webKey.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: modulus: %w", webKey.KeyID, err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(webKey.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: exponent: %w", webKey.KeyID, err)
+		}
+		e := new(big.Int).SetBytes(exponent)
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("key %q: invalid exponent", webKey.KeyID)
+		}
+		keys[webKey.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(e.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA signing key")
+	}
+	return keys, nil
+}
+
+// PublicJWK returns the JWKS entry of the public key, e.g. to publish the key of a local signing key.
+func PublicJWK(keyID string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": keyID,
+		"use": "sig",
+		"alg": RS256,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// ParseRSAPrivateKey parses a PEM encoded RSA private key, in PKCS #1 or PKCS #8 form.
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("jwt: the private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,228 @@
+// Package jwt verifies and signs the compact JSON Web Tokens of end users, with HS256 or RS256.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	// ErrInvalidToken is wrapped by the errors about malformed tokens, bad signatures and unexpected claims.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for a token past its 'exp'.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims are the registered claims the API uses. Times are seconds since the epoch, 0 when absent.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// Audience is the 'aud' claim, which is either a string or a list of strings.
+type Audience []string
+
+func (audience *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*audience = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*audience = list
+	return nil
+}
+
+func (audience Audience) MarshalJSON() ([]byte, error) {
+	if len(audience) == 1 {
+		return json.Marshal(audience[0])
+	}
+	return json.Marshal([]string(audience))
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// VerifierOptions configure a Verifier. At least one of HMACSecret and KeySet is required.
+type VerifierOptions struct {
+	HMACSecret []byte  // Verifies HS256 tokens
+	KeySet     *KeySet // Verifies RS256 tokens by their 'kid'
+	Issuer     string  // When set, 'iss' must match
+	Audience   string  // When set, 'aud' must contain it
+	Leeway     time.Duration
+}
+
+// Verifier checks the signature and the time, issuer and audience claims of tokens.
+type Verifier struct {
+	options VerifierOptions
+	now     func() time.Time
+}
+
+func NewVerifier(options VerifierOptions) (*Verifier, error) {
+	if len(options.HMACSecret) == 0 && options.KeySet == nil {
+		return nil, errors.New("jwt: an HMAC secret or a key set is required")
+	}
+	return &Verifier{options: options, now: time.Now}, nil
+}
+
+// Verify returns the claims of a valid token, which must have a subject.
+func (verifier *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected three parts", ErrInvalidToken)
+	}
+
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+	if err := verifier.verifySignature(head, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := verifier.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (verifier *Verifier) verifySignature(head header, signed string, signature []byte) error {
+	switch head.Algorithm {
+	case HS256:
+		if len(verifier.options.HMACSecret) == 0 {
+			return fmt.Errorf("%w: HS256 tokens are not accepted", ErrInvalidToken)
+		}
+		if !hmac.Equal(signature, hmacSHA256(verifier.options.HMACSecret, signed)) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	case RS256:
+		if verifier.options.KeySet == nil {
+			return fmt.Errorf("%w: RS256 tokens are not accepted", ErrInvalidToken)
+		}
+		key, err := verifier.options.KeySet.Key(head.KeyID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	default:
+		// Notably refuses "none"
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, head.Algorithm)
+	}
+}
+
+func (verifier *Verifier) validateClaims(claims Claims) error {
+	now := verifier.now()
+	leeway := verifier.options.Leeway
+
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	// A token without 'exp' would never expire, so it is refused
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if !now.Before(time.Unix(claims.ExpiresAt, 0).Add(leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if verifier.options.Issuer != "" && claims.Issuer != verifier.options.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if verifier.options.Audience != "" {
+		for _, audience := range claims.Audience {
+			if audience == verifier.options.Audience {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+// SignHS256 returns the token of the claims signed with the secret.
+func SignHS256(claims Claims, secret []byte) (string, error) {
+	signed, err := signingInput(header{Algorithm: HS256, Type: "JWT"}, claims)
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(hmacSHA256(secret, signed)), nil
+}
+
+// SignRS256 returns the token of the claims signed with the private key, with 'kid' in its header.
+func SignRS256(claims Claims, keyID string, key *rsa.PrivateKey) (string, error) {
+	signed, err := signingInput(header{Algorithm: RS256, Type: "JWT", KeyID: keyID}, claims)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func signingInput(head header, claims Claims) (string, error) {
+	headJSON, err := json.Marshal(head)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(headJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func hmacSHA256(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,412 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("test-secret-of-at-least-32-bytes!!")
+
+var (
+	rsaKeysOnce sync.Once
+	rsaKeys     [2]*rsa.PrivateKey
+)
+
+// testRSAKeys returns two RSA keys, generated once for all the tests.
+func testRSAKeys(t *testing.T) (*rsa.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+	rsaKeysOnce.Do(func() {
+		for i := range rsaKeys {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			rsaKeys[i] = key
+		}
+	})
+	return rsaKeys[0], rsaKeys[1]
+}
+
+// writeJWKS writes the public keys as a JWKS file and returns the key set loaded from it.
+func writeJWKS(t *testing.T, keys map[string]*rsa.PublicKey) *KeySet {
+	t.Helper()
+	document := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	for keyID, key := range keys {
+		document.Keys = append(document.Keys, PublicJWK(keyID, key))
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := NewKeySet(context.Background(), path, 0)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	return keySet
+}
+
+func newTestVerifier(t *testing.T, options VerifierOptions, now time.Time) *Verifier {
+	t.Helper()
+	verifier, err := NewVerifier(options)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	verifier.now = func() time.Time { return now }
+	return verifier
+}
+
+// unsignedToken builds a token with the header and claims and the raw signature, for tokens the signers refuse to make.
+func unsignedToken(t *testing.T, head header, claims Claims, signature []byte) string {
+	t.Helper()
+	signed, err := signingInput(head, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyHS256(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	verifier := newTestVerifier(t, VerifierOptions{HMACSecret: testSecret}, now)
+
+	token, err := SignHS256(Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("subject = %q, want user-1", claims.Subject)
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key, _ := testRSAKeys(t)
+	verifier := newTestVerifier(t, VerifierOptions{KeySet: writeJWKS(t, map[string]*rsa.PublicKey{"key-1": &key.PublicKey})}, now)
+
+	token, err := SignRS256(Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}, "key-1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("subject = %q, want user-1", claims.Subject)
+	}
+}
+
+func TestVerifyRefusesBadSignatures(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key, otherKey := testRSAKeys(t)
+	verifier := newTestVerifier(t, VerifierOptions{
+		HMACSecret: testSecret,
+		KeySet:     writeJWKS(t, map[string]*rsa.PublicKey{"key-1": &key.PublicKey}),
+	}, now)
+	claims := Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	wrongSecret, err := SignHS256(claims, []byte("another-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongKey, err := SignRS256(claims, "key-1", otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := SignHS256(claims, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	otherClaims, err := signingInput(header{Algorithm: HS256, Type: "JWT"}, Claims{Subject: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Split(otherClaims, ".")[0] + "." + strings.Split(otherClaims, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"HS256 with another secret", wrongSecret},
+		{"RS256 with another key", wrongKey},
+		{"claims changed after signing", tampered},
+		{"signature removed", parts[0] + "." + parts[1] + "."},
+		{"not three parts", parts[0] + "." + parts[1]},
+		{"signature not base64url", parts[0] + "." + parts[1] + ".!!!"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := verifier.Verify(test.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRefusesAlgNone(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	verifier := newTestVerifier(t, VerifierOptions{HMACSecret: testSecret}, now)
+
+	for _, algorithm := range []string{"none", "None", "NONE", ""} {
+		t.Run(algorithm, func(t *testing.T) {
+			token := unsignedToken(t, header{Algorithm: algorithm, Type: "JWT"}, Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}, nil)
+			if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRefusesAlgorithmConfusion(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key, _ := testRSAKeys(t)
+	keySet := writeJWKS(t, map[string]*rsa.PublicKey{"key-1": &key.PublicKey})
+	claims := Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	// The classic attack signs an HS256 token with the RSA public key as the HMAC secret
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	hsWithPublicKey, err := SignHS256(claims, publicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsWithModulus, err := SignHS256(claims, key.PublicKey.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsToken, err := SignRS256(claims, "key-1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsToken, err := SignHS256(claims, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		options  VerifierOptions
+		token    string
+		accepted bool
+	}{
+		{"HS256 signed with the public key PEM, RS256 only", VerifierOptions{KeySet: keySet}, hsWithPublicKey, false},
+		{"HS256 signed with the modulus, RS256 only", VerifierOptions{KeySet: keySet}, hsWithModulus, false},
+		{"HS256 signed with the public key PEM, both", VerifierOptions{HMACSecret: testSecret, KeySet: keySet}, hsWithPublicKey, false},
+		{"RS256 token, HS256 only", VerifierOptions{HMACSecret: testSecret}, rsToken, false},
+		{"HS256 token, RS256 only", VerifierOptions{KeySet: keySet}, hsToken, false},
+		{"RS256 token, both", VerifierOptions{HMACSecret: testSecret, KeySet: keySet}, rsToken, true},
+		{"HS256 token, both", VerifierOptions{HMACSecret: testSecret, KeySet: keySet}, hsToken, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := newTestVerifier(t, test.options, now)
+			_, err := verifier.Verify(test.token)
+			if test.accepted && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if !test.accepted && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTimeClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		leeway  time.Duration
+		wantErr error
+	}{
+		{"no expiry", Claims{}, 0, ErrInvalidToken},
+		{"no expiry but valid from", Claims{NotBefore: now.Add(-time.Minute).Unix()}, 0, ErrInvalidToken},
+		{"valid window", Claims{NotBefore: now.Add(-time.Minute).Unix(), ExpiresAt: now.Add(time.Minute).Unix()}, 0, nil},
+		{"expired", Claims{ExpiresAt: now.Add(-time.Second).Unix()}, 0, ErrTokenExpired},
+		{"expiring now", Claims{ExpiresAt: now.Unix()}, 0, ErrTokenExpired},
+		{"expired within the leeway", Claims{ExpiresAt: now.Add(-time.Second).Unix()}, time.Minute, nil},
+		{"not valid yet", Claims{NotBefore: now.Add(time.Minute).Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, 0, ErrInvalidToken},
+		{"not valid yet within the leeway", Claims{NotBefore: now.Add(time.Second).Unix(), ExpiresAt: now.Add(time.Hour).Unix()}, time.Minute, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := newTestVerifier(t, VerifierOptions{HMACSecret: testSecret, Leeway: test.leeway}, now)
+			test.claims.Subject = "user-1"
+			token, err := SignHS256(test.claims, testSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = verifier.Verify(token)
+			if test.wantErr == nil && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("Verify error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyIssuerAndAudience(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	options := VerifierOptions{HMACSecret: testSecret, Issuer: "https://auth.example.com", Audience: "news-api"}
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		accepted bool
+	}{
+		{"matching", Claims{Issuer: "https://auth.example.com", Audience: Audience{"news-api"}}, true},
+		{"audience in a list", Claims{Issuer: "https://auth.example.com", Audience: Audience{"other", "news-api"}}, true},
+		{"other issuer", Claims{Issuer: "https://evil.example.com", Audience: Audience{"news-api"}}, false},
+		{"no issuer", Claims{Audience: Audience{"news-api"}}, false},
+		{"other audience", Claims{Issuer: "https://auth.example.com", Audience: Audience{"other"}}, false},
+		{"no audience", Claims{Issuer: "https://auth.example.com"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := newTestVerifier(t, options, now)
+			test.claims.Subject = "user-1"
+			test.claims.ExpiresAt = now.Add(time.Hour).Unix()
+			token, err := SignHS256(test.claims, testSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = verifier.Verify(token)
+			if test.accepted && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if !test.accepted && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRequiresSubject(t *testing.T) {
+	verifier := newTestVerifier(t, VerifierOptions{HMACSecret: testSecret}, time.Unix(1_700_000_000, 0))
+	token, err := SignHS256(Claims{ExpiresAt: time.Unix(1_700_000_000, 0).Add(time.Hour).Unix()}, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	var claims Claims
+	if err := json.Unmarshal([]byte(`{"sub":"user-1","aud":"news-api"}`), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "news-api" {
+		t.Errorf("audience = %v, want [news-api]", claims.Audience)
+	}
+	if err := json.Unmarshal([]byte(`{"sub":"user-1","aud":["a","b"]}`), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Audience) != 2 {
+		t.Errorf("audience = %v, want [a b]", claims.Audience)
+	}
+}
+
+func TestKeySetKeyIDLookup(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key, otherKey := testRSAKeys(t)
+	claims := Claims{Subject: "user-1", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	twoKeys := newTestVerifier(t, VerifierOptions{KeySet: writeJWKS(t, map[string]*rsa.PublicKey{
+		"key-1": &key.PublicKey,
+		"key-2": &otherKey.PublicKey,
+	})}, now)
+	oneKey := newTestVerifier(t, VerifierOptions{KeySet: writeJWKS(t, map[string]*rsa.PublicKey{
+		"key-1": &key.PublicKey,
+	})}, now)
+
+	sign := func(keyID string, signingKey *rsa.PrivateKey) string {
+		token, err := SignRS256(claims, keyID, signingKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+		accepted bool
+	}{
+		{"first key by kid", twoKeys, sign("key-1", key), true},
+		{"second key by kid", twoKeys, sign("key-2", otherKey), true},
+		{"kid of another key", twoKeys, sign("key-2", key), false},
+		{"unknown kid", twoKeys, sign("key-3", key), false},
+		{"no kid with several keys", twoKeys, sign("", key), false},
+		{"no kid with a single key", oneKey, sign("", key), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.verifier.Verify(test.token)
+			if test.accepted && err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if !test.accepted && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestParseJWKSSkipsOtherKeys(t *testing.T) {
+	key, _ := testRSAKeys(t)
+	signing := PublicJWK("key-1", &key.PublicKey)
+	encryption := PublicJWK("key-2", &key.PublicKey)
+	encryption["use"] = "enc"
+	data, err := json.Marshal(map[string]interface{}{"keys": []interface{}{
+		signing,
+		encryption,
+		map[string]string{"kty": "EC", "kid": "key-3"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	if len(keys) != 1 || keys["key-1"] == nil {
+		t.Errorf("keys = %v, want only key-1", keys)
+	}
+	if keys["key-1"].E != key.PublicKey.E || keys["key-1"].N.Cmp(key.PublicKey.N) != 0 {
+		t.Error("key-1 does not match the public key")
+	}
+
+	if _, err := ParseJWKS([]byte(`{"keys":[]}`)); err == nil {
+		t.Error("ParseJWKS of no keys succeeded")
+	}
+}
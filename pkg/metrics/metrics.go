@@ -0,0 +1,172 @@
+// Package metrics is a minimal in-process metrics registry exposed in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+type metric interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]metric{}
+)
+
+// register adds the metric under its name. Registering a name twice is a programming error.
+func register(name string, m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("metrics: %s is already registered", name))
+	}
+	registry[name] = m
+}
+
+// Counter is a value which only goes up.
+type Counter struct {
+	name, help string
+	value      atomic.Uint64 // float64 bits
+}
+
+func NewCounter(name string, help string) *Counter {
+	counter := &Counter{name: name, help: help}
+	register(name, counter)
+	return counter
+}
+
+func (counter *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	addFloat(&counter.value, delta)
+}
+
+func (counter *Counter) Inc() {
+	counter.Add(1)
+}
+
+func (counter *Counter) write(w io.Writer) {
+	writeHeader(w, counter.name, counter.help, "counter")
+	fmt.Fprintf(w, "%s %s\n", counter.name, formatFloat(math.Float64frombits(counter.value.Load())))
+}
+
+// Gauge is a value which can go up and down.
+type Gauge struct {
+	name, help string
+	value      atomic.Uint64 // float64 bits
+}
+
+func NewGauge(name string, help string) *Gauge {
+	gauge := &Gauge{name: name, help: help}
+	register(name, gauge)
+	return gauge
+}
+
+func (gauge *Gauge) Set(value float64) {
+	gauge.value.Store(math.Float64bits(value))
+}
+
+func (gauge *Gauge) Add(delta float64) {
+	addFloat(&gauge.value, delta)
+}
+
+func (gauge *Gauge) write(w io.Writer) {
+	writeHeader(w, gauge.name, gauge.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", gauge.name, formatFloat(math.Float64frombits(gauge.value.Load())))
+}
+
+// Histogram counts observations into cumulative buckets with the given upper bounds.
+type Histogram struct {
+	name, help string
+	mu         sync.Mutex
+	bounds     []float64
+	counts     []uint64 // counts[i] is the number of observations <= bounds[i]
+	count      uint64
+	sum        float64
+}
+
+func NewHistogram(name string, help string, bounds []float64) *Histogram {
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	histogram := &Histogram{name: name, help: help, bounds: sorted, counts: make([]uint64, len(sorted))}
+	register(name, histogram)
+	return histogram
+}
+
+func (histogram *Histogram) Observe(value float64) {
+	histogram.mu.Lock()
+	defer histogram.mu.Unlock()
+	for i, bound := range histogram.bounds {
+		if value <= bound {
+			histogram.counts[i]++
+		}
+	}
+	histogram.count++
+	histogram.sum += value
+}
+
+func (histogram *Histogram) write(w io.Writer) {
+	histogram.mu.Lock()
+	defer histogram.mu.Unlock()
+	writeHeader(w, histogram.name, histogram.help, "histogram")
+	for i, bound := range histogram.bounds {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", histogram.name, formatFloat(bound), histogram.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", histogram.name, histogram.count)
+	fmt.Fprintf(w, "%s_sum %s\n", histogram.name, formatFloat(histogram.sum))
+	fmt.Fprintf(w, "%s_count %d\n", histogram.name, histogram.count)
+}
+
+// WriteText writes every registered metric, sorted by name, in the Prometheus text format.
+func WriteText(w io.Writer) {
+	registryMu.Lock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = registry[name]
+	}
+	registryMu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Handler serves the registered metrics for a Prometheus scrape.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+func addFloat(value *atomic.Uint64, delta float64) {
+	for {
+		old := value.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if value.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+func writeHeader(w io.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,136 @@
+// Package ratelimit limits clients with token buckets, kept in a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: it holds up to Burst tokens and refills at Rate tokens per second.
+// Every request takes a token.
+type Limit struct {
+	Rate  float64
+	Burst float64
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until a token is available, 0 when the request is allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets by key. Take must refill and take from the bucket atomically.
+// Peek tells whether a request would be allowed, without taking a token.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Refill returns the tokens of a bucket which had 'tokens' at 'updatedAt', refilled until 'now'.
+func Refill(tokens float64, updatedAt time.Time, limit Limit, now time.Time) float64 {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(limit.Burst, tokens+elapsed*limit.Rate)
+}
+
+// NewResult describes a bucket left with 'tokens' after a request which was 'allowed' or not.
+func NewResult(allowed bool, tokens float64, limit Limit) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     int(limit.Burst),
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsDuration((limit.Burst - tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = secondsDuration((1 - tokens) / limit.Rate)
+	}
+	return result
+}
+
+// FullAfter is how long an emptied bucket takes to refill, after which its state can be forgotten.
+func FullAfter(limit Limit) time.Duration {
+	return secondsDuration(limit.Burst / limit.Rate)
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// minSweepSize is the number of buckets a MemoryStore holds before it first removes the full ones
+const minSweepSize = 1024
+
+// MemoryStore keeps the buckets in memory, so every instance limits its clients separately.
+// Buckets which refilled completely are removed whenever the store doubles in size.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	sweepSize int
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}, sweepSize: minSweepSize}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	bucket, ok := store.buckets[key]
+	if !ok {
+		if len(store.buckets) >= store.sweepSize {
+			store.sweep(now)
+		}
+		bucket = &memoryBucket{tokens: limit.Burst, updatedAt: now}
+		store.buckets[key] = bucket
+	}
+
+	bucket.tokens = Refill(bucket.tokens, bucket.updatedAt, limit, now)
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	if now.After(bucket.updatedAt) {
+		bucket.updatedAt = now
+	}
+	bucket.fullAt = bucket.updatedAt.Add(secondsDuration((limit.Burst - bucket.tokens) / limit.Rate))
+	return NewResult(allowed, bucket.tokens, limit), nil
+}
+
+func (store *MemoryStore) Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	tokens := limit.Burst
+	if bucket, ok := store.buckets[key]; ok {
+		tokens = Refill(bucket.tokens, bucket.updatedAt, limit, now)
+	}
+	return NewResult(tokens >= 1, tokens, limit), nil
+}
+
+// sweep removes the buckets which are full by now, as a new bucket would be the same.
+func (store *MemoryStore) sweep(now time.Time) {
+	for key, bucket := range store.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(store.buckets, key)
+		}
+	}
+	store.sweepSize = max(minSweepSize, 2*len(store.buckets))
+}
@@ -0,0 +1,33 @@
+// Package reqctx carries who made a request through its context, for logging and quotas.
+package reqctx
+
+import "context"
+
+type contextKey int
+
+const (
+	apiKeyIDKey contextKey = iota
+	userIDKey
+)
+
+// WithAPIKeyID returns a copy of the context carrying the ID of the API key which authenticated the request.
+func WithAPIKeyID(ctx context.Context, apiKeyID string) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey, apiKeyID)
+}
+
+// APIKeyID returns the ID of the API key which authenticated the request, or "" when there is none.
+func APIKeyID(ctx context.Context) string {
+	apiKeyID, _ := ctx.Value(apiKeyIDKey).(string)
+	return apiKeyID
+}
+
+// WithUserID returns a copy of the context carrying the end user authenticated by the request's bearer token.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the end user authenticated by the request's bearer token, or "" when there is none.
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
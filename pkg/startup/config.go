@@ -2,7 +2,9 @@ package startup
 
 import (
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,103 @@ type Config struct {
 	LLMToken               	string
 	LLMEndpoint            	string
 	LLMModel               	string
+	// LLM backend, one of openrouter, openai (any OpenAI compatible API), ollama or fake, and the JSON file
+	// of the fake backend's scripted responses (built-in templated ones when empty)
+	LLMProvider            	string
+	LLMFakeResponses       	string
+	ReverseGeocoder         string
+	GazetteerPath           string
+	GazetteerMaxDistanceKm  float64
+	// Weights and decays used by the nearby 'rank=blend' ranking
+	NearbyBlendDistanceWeight  float64
+	NearbyBlendRecencyWeight   float64
+	NearbyBlendRelevanceWeight float64
+	NearbyBlendDistanceHalfKm  float64
+	NearbyBlendRecencyHalfLife time.Duration
+	// Radius steps used to widen a nearby search with 'min_results'
+	NearbyRadiusStepsKm []float64
+	NearbyMaxRadiusKm   float64
+	// Trend score weight of each event type, and the decay half-life of each trending window
+	TrendingEventWeights map[string]float64
+	TrendingHalfLives    map[string]time.Duration
+	// Background trending snapshots, per window, category and geo cell
+	TrendingSnapshotsEnabled  bool
+	TrendingSnapshotInterval  time.Duration
+	TrendingSnapshotCellDeg   float64
+	TrendingSnapshotSize      int
+	TrendingSnapshotRetention time.Duration
+	// Asynchronous user event writer; a full queue either drops events or rejects the batch with 503
+	EventQueueSize     int
+	EventBatchSize     int
+	EventFlushInterval time.Duration
+	EventQueueFullMode string
+	// Personalized feed: the event weights and decay of user interest profiles, and the feed ranking
+	UserProfileEventWeights map[string]float64
+	UserProfileHalfLife     time.Duration
+	UserProfileHistory      time.Duration
+	UserProfileCacheTTL     time.Duration
+	FeedAffinityWeight      float64
+	FeedFreshnessWeight     float64
+	FeedRelevanceWeight     float64
+	FeedFreshnessHalfLife   time.Duration
+	FeedLocalRadiusKm       float64
+	// "Also read" item-to-item recommendations, rebuilt from session co-occurrence every interval
+	AlsoReadEnabled         bool
+	AlsoReadInterval        time.Duration
+	AlsoReadHistory         time.Duration
+	AlsoReadNeighbors       int
+	AlsoReadMinCooccurrence int
+	FeedAlsoReadWeight      float64
+	// Read history: how many of each user's latest read articles are kept for 'exclude_seen'
+	ReadHistorySize int
+	// API keys: whether routes require one, how long validated keys are cached and how long a rotated secret keeps working
+	APIKeysEnabled      bool
+	APIKeyCacheTTL      time.Duration
+	APIKeyRotationGrace time.Duration
+	// End-user bearer JWTs: HS256 secret and/or RS256 JWKS (file path or URL), expected claims and clock leeway,
+	// how often a JWKS URL is reloaded, and whether user-scoped routes require a token
+	JWTHS256Secret string
+	JWTJWKS        string
+	JWTIssuer      string
+	JWTAudience    string
+	JWTLeeway      time.Duration
+	JWTJWKSRefresh time.Duration
+	JWTRequired    bool
+	// Rate limits: token buckets per client and route group, refilled at the requests per minute and holding
+	// the burst, kept in memory or in MongoDB ("memory" or "mongo") to share them between instances
+	RateLimitEnabled   bool
+	RateLimitStore     string
+	RateLimitPerMinute map[string]float64
+	RateLimitBurst     map[string]float64
+	// TrustedProxies are the IPs or CIDRs of the proxies whose X-Forwarded-For gives the client IP,
+	// which keyless clients are limited by; none by default, the client IP then being the peer's
+	TrustedProxies []string
+	// LLM usage: USD per million prompt and completion tokens by model, the default daily and monthly
+	// budgets of API keys (0 for none), what happens over budget and how long spends are cached
+	LLMPromptPrices       map[string]float64
+	LLMCompletionPrices   map[string]float64
+	LLMDailyBudgetUSD     float64
+	LLMMonthlyBudgetUSD   float64
+	LLMBudgetExceededMode string
+	LLMSpendCacheTTL      time.Duration
+	// Summary generation: summaries generated in parallel per request, LLM calls in flight at once
+	// over all requests (0 for no limit), how long generating one summary may take and how long a request
+	// waits for its summaries (0 for as long as the request lasts); the queue and workers of the
+	// summaries generated in the background for lazy listings
+	SummaryConcurrency   int
+	LLMMaxConcurrency    int
+	SummaryTimeout       time.Duration
+	SummaryRequestBudget time.Duration
+	SummaryQueueSize     int
+	SummaryWorkers       int
+	// Background summary pre-generation of the articles published within the window, at most MaxPerRun
+	// summaries per run and PerMinute per minute; a failed summary is retried after RetryBackoff, doubled per attempt
+	SummaryPregenEnabled   bool
+	SummaryPregenInterval  time.Duration
+	SummaryPregenWindow    time.Duration
+	SummaryPregenMaxPerRun int
+	SummaryPregenPerMinute float64
+	SummaryRetryBackoff    time.Duration
 }
 
 func LoadConfig(path ...string) (*Config, error) {
@@ -46,6 +145,116 @@ func LoadConfig(path ...string) (*Config, error) {
 		LLMToken:               getEnv("LLM_TOKEN", ""),
 		LLMEndpoint:            getEnv("LLM_ENDPOINT", ""),
 		LLMModel:               getEnv("LLM_MODEL", "gpt-4o"),
+		LLMProvider:            getEnv("LLM_PROVIDER", "openrouter"),
+		LLMFakeResponses:       getEnv("LLM_FAKE_RESPONSES", ""),
+		ReverseGeocoder:        getEnv("REVERSE_GEOCODER", "offline"),
+		GazetteerPath:          getEnv("GAZETTEER_PATH", "../../data/gazetteer.json"),
+		GazetteerMaxDistanceKm: getEnvFloat("GAZETTEER_MAX_DISTANCE_KM", 100),
+		NearbyBlendDistanceWeight:  getEnvFloat("NEARBY_BLEND_DISTANCE_WEIGHT", 0.5),
+		NearbyBlendRecencyWeight:   getEnvFloat("NEARBY_BLEND_RECENCY_WEIGHT", 0.3),
+		NearbyBlendRelevanceWeight: getEnvFloat("NEARBY_BLEND_RELEVANCE_WEIGHT", 0.2),
+		NearbyBlendDistanceHalfKm:  getEnvFloat("NEARBY_BLEND_DISTANCE_HALF_KM", 5),
+		NearbyBlendRecencyHalfLife: time.Duration(getEnvFloat("NEARBY_BLEND_RECENCY_HALF_LIFE_HOURS", 24) * float64(time.Hour)),
+		NearbyRadiusStepsKm:        getEnvFloatList("NEARBY_RADIUS_STEPS_KM", []float64{1, 5, 25, 100, 500}),
+		NearbyMaxRadiusKm:          getEnvFloat("NEARBY_MAX_RADIUS_KM", 500),
+		TrendingEventWeights: getEnvFloatMap("TRENDING_EVENT_WEIGHTS", map[string]float64{
+			"view":     1,
+			"dwell":    2,
+			"click":    3,
+			"bookmark": 4,
+			"share":    5,
+		}),
+		TrendingHalfLives: getEnvDurationMap("TRENDING_HALF_LIVES", map[string]time.Duration{
+			"1h":  15 * time.Minute,
+			"24h": 6 * time.Hour,
+			"7d":  36 * time.Hour,
+		}),
+		TrendingSnapshotsEnabled:  getEnvBool("TRENDING_SNAPSHOTS_ENABLED", true),
+		TrendingSnapshotInterval:  getEnvDuration("TRENDING_SNAPSHOT_INTERVAL", 5*time.Minute),
+		TrendingSnapshotCellDeg:   getEnvFloat("TRENDING_SNAPSHOT_CELL_DEG", 1),
+		TrendingSnapshotSize:      getEnvInt("TRENDING_SNAPSHOT_SIZE", 200),
+		TrendingSnapshotRetention: getEnvDuration("TRENDING_SNAPSHOT_RETENTION", 7*24*time.Hour),
+		EventQueueSize:            getEnvInt("EVENT_QUEUE_SIZE", 10000),
+		EventBatchSize:            getEnvInt("EVENT_BATCH_SIZE", 500),
+		EventFlushInterval:        getEnvDuration("EVENT_FLUSH_INTERVAL", time.Second),
+		EventQueueFullMode:        getEnv("EVENT_QUEUE_FULL_MODE", "reject"),
+		UserProfileEventWeights: getEnvFloatMap("USER_PROFILE_EVENT_WEIGHTS", map[string]float64{
+			"view":     1,
+			"click":    3,
+			"bookmark": 4,
+		}),
+		UserProfileHalfLife:   getEnvDuration("USER_PROFILE_HALF_LIFE", 14*24*time.Hour),
+		UserProfileHistory:    getEnvDuration("USER_PROFILE_HISTORY", 30*24*time.Hour),
+		UserProfileCacheTTL:   getEnvDuration("USER_PROFILE_CACHE_TTL", 10*time.Minute),
+		FeedAffinityWeight:    getEnvFloat("FEED_AFFINITY_WEIGHT", 0.6),
+		FeedFreshnessWeight:   getEnvFloat("FEED_FRESHNESS_WEIGHT", 0.25),
+		FeedRelevanceWeight:   getEnvFloat("FEED_RELEVANCE_WEIGHT", 0.15),
+		FeedFreshnessHalfLife: getEnvDuration("FEED_FRESHNESS_HALF_LIFE", 48*time.Hour),
+		FeedLocalRadiusKm:     getEnvFloat("FEED_LOCAL_RADIUS_KM", 50),
+		AlsoReadEnabled:         getEnvBool("ALSO_READ_ENABLED", true),
+		AlsoReadInterval:        getEnvDuration("ALSO_READ_INTERVAL", time.Hour),
+		AlsoReadHistory:         getEnvDuration("ALSO_READ_HISTORY", 30*24*time.Hour),
+		AlsoReadNeighbors:       getEnvInt("ALSO_READ_NEIGHBORS", 20),
+		AlsoReadMinCooccurrence: getEnvInt("ALSO_READ_MIN_COOCCURRENCE", 2),
+		FeedAlsoReadWeight:      getEnvFloat("FEED_ALSO_READ_WEIGHT", 0.3),
+		ReadHistorySize:         getEnvPositiveInt("READ_HISTORY_SIZE", 1000),
+		APIKeysEnabled:          getEnvBool("API_KEYS_ENABLED", true),
+		APIKeyCacheTTL:          getEnvDuration("API_KEY_CACHE_TTL", time.Minute),
+		APIKeyRotationGrace:     getEnvDuration("API_KEY_ROTATION_GRACE", 24*time.Hour),
+		JWTHS256Secret:          getEnv("JWT_HS256_SECRET", ""),
+		JWTJWKS:                 getEnv("JWT_JWKS", ""),
+		JWTIssuer:               getEnv("JWT_ISSUER", ""),
+		JWTAudience:             getEnv("JWT_AUDIENCE", ""),
+		JWTLeeway:               getEnvDuration("JWT_LEEWAY", time.Minute),
+		JWTJWKSRefresh:          getEnvDuration("JWT_JWKS_REFRESH", time.Hour),
+		JWTRequired:             getEnvBool("JWT_REQUIRED", false),
+		RateLimitEnabled:        getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitStore:          getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitPerMinute: getEnvFloatMap("RATE_LIMIT_PER_MINUTE", map[string]float64{
+			"news":   120,
+			"llm":    10,
+			"events": 600,
+			"users":  120,
+			"admin":  60,
+			"auth":   10,
+		}),
+		RateLimitBurst: getEnvFloatMap("RATE_LIMIT_BURST", map[string]float64{
+			"news":   30,
+			"llm":    3,
+			"events": 100,
+			"users":  30,
+			"admin":  10,
+			"auth":   10,
+		}),
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+		LLMPromptPrices: getEnvFloatMap("LLM_PROMPT_PRICES", map[string]float64{
+			"gpt-4o":             2.5,
+			"openai/gpt-4o":      2.5,
+			"gpt-4o-mini":        0.15,
+			"openai/gpt-4o-mini": 0.15,
+		}),
+		LLMCompletionPrices: getEnvFloatMap("LLM_COMPLETION_PRICES", map[string]float64{
+			"gpt-4o":             10,
+			"openai/gpt-4o":      10,
+			"gpt-4o-mini":        0.6,
+			"openai/gpt-4o-mini": 0.6,
+		}),
+		LLMDailyBudgetUSD:      getEnvFloat("LLM_DAILY_BUDGET_USD", 0),
+		LLMMonthlyBudgetUSD:    getEnvFloat("LLM_MONTHLY_BUDGET_USD", 0),
+		LLMBudgetExceededMode:  getEnv("LLM_BUDGET_EXCEEDED_MODE", "skip_summaries"),
+		LLMSpendCacheTTL:       getEnvDuration("LLM_SPEND_CACHE_TTL", 30*time.Second),
+		SummaryConcurrency:     getEnvInt("SUMMARY_CONCURRENCY", 4),
+		LLMMaxConcurrency:      getEnvInt("LLM_MAX_CONCURRENCY", 16),
+		SummaryTimeout:         getEnvDuration("SUMMARY_TIMEOUT", time.Minute),
+		SummaryRequestBudget:   getEnvDuration("SUMMARY_REQUEST_BUDGET", 10*time.Second),
+		SummaryQueueSize:       getEnvInt("SUMMARY_QUEUE_SIZE", 1000),
+		SummaryWorkers:         getEnvInt("SUMMARY_WORKERS", 2),
+		SummaryPregenEnabled:   getEnvBool("SUMMARY_PREGEN_ENABLED", true),
+		SummaryPregenInterval:  getEnvDuration("SUMMARY_PREGEN_INTERVAL", 10*time.Minute),
+		SummaryPregenWindow:    getEnvDuration("SUMMARY_PREGEN_WINDOW", 72*time.Hour),
+		SummaryPregenMaxPerRun: getEnvInt("SUMMARY_PREGEN_MAX_PER_RUN", 200),
+		SummaryPregenPerMinute: getEnvFloat("SUMMARY_PREGEN_PER_MINUTE", 30),
+		SummaryRetryBackoff:    getEnvDuration("SUMMARY_RETRY_BACKOFF", 5*time.Minute),
 	}, nil
 }
 
@@ -56,3 +265,132 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvPositiveInt reads a count which must be at least 1.
+// The default is used if the value is not a positive integer.
+func getEnvPositiveInt(key string, defaultValue int) int {
+	value := getEnvInt(key, defaultValue)
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDuration reads a Go duration, e.g. "5m" or "36h".
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloatList reads a comma separated list of numbers, sorted ascending.
+// The default is used if any value is not a number.
+func getEnvFloatList(key string, defaultValue []float64) []float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var numbers []float64
+	for _, part := range strings.Split(value, ",") {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return defaultValue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Float64s(numbers)
+	return numbers
+}
+
+// getEnvFloatMap reads "key=number" pairs separated by commas, e.g. "view=1,click=3".
+// The default is used if any pair is malformed.
+func getEnvFloatMap(key string, defaultValue map[string]float64) map[string]float64 {
+	pairs, ok := getEnvPairs(key)
+	if !ok {
+		return defaultValue
+	}
+
+	result := make(map[string]float64, len(pairs))
+	for name, value := range pairs {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return defaultValue
+		}
+		result[name] = number
+	}
+	return result
+}
+
+// getEnvDurationMap reads "key=duration" pairs separated by commas, e.g. "1h=15m,24h=6h".
+// The default is used if any pair is malformed.
+func getEnvDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	pairs, ok := getEnvPairs(key)
+	if !ok {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration, len(pairs))
+	for name, value := range pairs {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return defaultValue
+		}
+		result[name] = duration
+	}
+	return result
+}
+
+// getEnvList reads a comma separated list, nil when it is not set.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvPairs(key string) (map[string]string, bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, false
+	}
+
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, false
+		}
+		pairs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return pairs, true
+}
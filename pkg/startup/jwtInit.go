@@ -0,0 +1,37 @@
+package startup
+
+import (
+	"errors"
+
+	"github.com/shivam-cse/contextual-news-api/pkg/jwt"
+)
+
+// NewJWTVerifier builds the verifier of end-user bearer tokens from the config.
+// It returns nil when neither an HS256 secret nor a JWKS is configured, which is an error when tokens are required.
+func NewJWTVerifier(config *Config) (*jwt.Verifier, error) {
+	if config.JWTHS256Secret == "" && config.JWTJWKS == "" {
+		if config.JWTRequired {
+			return nil, errors.New("JWT_REQUIRED needs JWT_HS256_SECRET or JWT_JWKS")
+		}
+		return nil, nil
+	}
+
+	options := jwt.VerifierOptions{
+		Issuer:   config.JWTIssuer,
+		Audience: config.JWTAudience,
+		Leeway:   config.JWTLeeway,
+	}
+	if config.JWTHS256Secret != "" {
+		options.HMACSecret = []byte(config.JWTHS256Secret)
+	}
+	if config.JWTJWKS != "" {
+		ctx, cancel := GetContext(10)
+		defer cancel()
+		keySet, err := jwt.NewKeySet(ctx, config.JWTJWKS, config.JWTJWKSRefresh)
+		if err != nil {
+			return nil, err
+		}
+		options.KeySet = keySet
+	}
+	return jwt.NewVerifier(options)
+}
@@ -86,8 +86,175 @@ func CreateIndexOnNewsColl(db *mongo.Database) error {
 				},
 			),
 		},
+		{
+			// Supports the 'country' and 'region' filters on the listing endpoints
+			Keys: bson.D{
+				primitive.E{Key: "country_code", Value: 1},
+				primitive.E{Key: "region", Value: 1},
+			},
+		},
     }
 
 	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
 	return err
-}
\ No newline at end of file
+}
+
+func CreateIndexOnUserEventColl(db *mongo.Database) error {
+	// Create an index on the timestamp field for the trending window,
+	// on article_id and timestamp for the per-article event lookups
+	// and a 2dsphere index on the location field for location-aware trending.
+	// client_event_id is unique to drop retried events, partial since older events have none
+	collection := db.Collection(constants.USER_EVENT)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "location", Value: "2dsphere"},
+			},
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "timestamp", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "article_id", Value: 1},
+				primitive.E{Key: "timestamp", Value: -1},
+			},
+		},
+		{
+			// Reads a user's history for their interest profile
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "timestamp", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "client_event_id", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"client_event_id": bson.M{"$type": "string"}}),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
+
+func CreateIndexOnTrendingSnapshotColl(db *mongo.Database, retention time.Duration) error {
+	// Create an index for the latest snapshot lookup of a window, category and cell
+	// and a TTL index on as_of so that old snapshots expire after the retention
+	collection := db.Collection(constants.TRENDING_SNAPSHOTS)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "window", Value: 1},
+				primitive.E{Key: "category", Value: 1},
+				primitive.E{Key: "cell", Value: 1},
+				primitive.E{Key: "as_of", Value: -1},
+			},
+		},
+	}
+	if retention > 0 {
+		indexModel = append(indexModel, mongo.IndexModel{
+			Keys: bson.D{
+				primitive.E{Key: "as_of", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention.Seconds())),
+		})
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
+
+func CreateIndexOnBookmarkColl(db *mongo.Database) error {
+	// Create a unique index so that an article is only once in each collection of a user,
+	// and an index for listing a user's latest bookmarks
+	collection := db.Collection(constants.BOOKMARKS)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "collection", Value: 1},
+				primitive.E{Key: "article_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
+
+func CreateIndexOnRateLimitColl(db *mongo.Database) error {
+	// Create a TTL index on expires_at so that buckets are removed once they are full again
+	collection := db.Collection(constants.RATE_LIMITS)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "expires_at", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
+
+func CreateIndexOnLLMUsageColl(db *mongo.Database) error {
+	// Create a unique index for the usage of a key with a model on a day,
+	// and an index for the usage of all keys over a range of days
+	collection := db.Collection(constants.LLM_USAGE)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "api_key_id", Value: 1},
+				primitive.E{Key: "day", Value: 1},
+				primitive.E{Key: "model", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "day", Value: -1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
+
+func CreateIndexOnSummaryColl(db *mongo.Database) error {
+	// Create a unique index for the summary of an article by a model with a prompt version
+	collection := db.Collection(constants.SUMMARIES)
+
+	indexModel := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				primitive.E{Key: "article_id", Value: 1},
+				primitive.E{Key: "model", Value: 1},
+				primitive.E{Key: "prompt_version", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(context.Background(), indexModel)
+	return err
+}
@@ -0,0 +1,22 @@
+package startup
+
+import (
+	"github.com/shivam-cse/contextual-news-api/pkg/ratelimit"
+)
+
+// RateLimits returns the token buckets of the route groups from the config. Groups with no positive rate
+// are left out, which leaves them unlimited, and the burst is at least one request.
+func RateLimits(config *Config) map[string]ratelimit.Limit {
+	limits := map[string]ratelimit.Limit{}
+	for group, perMinute := range config.RateLimitPerMinute {
+		if perMinute <= 0 {
+			continue
+		}
+		burst := config.RateLimitBurst[group]
+		if burst < 1 {
+			burst = 1
+		}
+		limits[group] = ratelimit.Limit{Rate: perMinute / 60, Burst: burst}
+	}
+	return limits
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// bboxEdgeStepDeg is the spacing of the extra points added along the top and bottom edges of a bounding box.
+// MongoDB treats polygon edges as geodesics, so without them a wide box bulges towards the poles.
+const bboxEdgeStepDeg = 1.0
+
+// Geometry is a GeoJSON Polygon or MultiPolygon which can be used directly in a $geoWithin query.
+type Geometry struct {
+	Type        string      `json:"type" bson:"type"`
+	Coordinates interface{} `json:"coordinates" bson:"coordinates"`
+}
+
+type ring [][]float64
+type polygon []ring
+
+// BBox is a bounding box in the "minLon,minLat,maxLon,maxLat" order used by GeoJSON.
+// MinLon greater than MaxLon means the box crosses the antimeridian.
+type BBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// ParseBBox parses and validates a "minLon,minLat,maxLon,maxLat" string.
+func ParseBBox(value string) (BBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return BBox{}, errors.New("bbox must be 'minLon,minLat,maxLon,maxLat'")
+	}
+
+	numbers := make([]float64, 4)
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BBox{}, fmt.Errorf("bbox value %q is not a number", part)
+		}
+		numbers[i] = number
+	}
+
+	bbox := BBox{MinLon: numbers[0], MinLat: numbers[1], MaxLon: numbers[2], MaxLat: numbers[3]}
+	if err := validatePosition(bbox.MinLon, bbox.MinLat); err != nil {
+		return BBox{}, err
+	}
+	if err := validatePosition(bbox.MaxLon, bbox.MaxLat); err != nil {
+		return BBox{}, err
+	}
+	if bbox.MinLat >= bbox.MaxLat {
+		return BBox{}, errors.New("bbox minLat must be less than maxLat")
+	}
+	if bbox.MinLon == bbox.MaxLon {
+		return BBox{}, errors.New("bbox minLon and maxLon must differ")
+	}
+	return bbox, nil
+}
+
+// CrossesAntimeridian reports whether the box wraps around longitude 180.
+func (bbox BBox) CrossesAntimeridian() bool {
+	return bbox.MinLon > bbox.MaxLon
+}
+
+// Contains reports whether the coordinate lies inside the box.
+func (bbox BBox) Contains(latitude float64, longitude float64) bool {
+	if latitude < bbox.MinLat || latitude > bbox.MaxLat {
+		return false
+	}
+	if bbox.CrossesAntimeridian() {
+		return longitude >= bbox.MinLon || longitude <= bbox.MaxLon
+	}
+	return longitude >= bbox.MinLon && longitude <= bbox.MaxLon
+}
+
+// Geometry converts the box into a polygon for $geoWithin.
+// Boxes that cross the antimeridian or are wider than 180 degrees are split into a MultiPolygon,
+// since a single polygon ring is always interpreted as the smaller of the two possible areas.
+func (bbox BBox) Geometry() Geometry {
+	spans := [][2]float64{{bbox.MinLon, bbox.MaxLon}}
+	if bbox.CrossesAntimeridian() {
+		spans = [][2]float64{{bbox.MinLon, 180}, {-180, bbox.MaxLon}}
+	}
+
+	polygons := []polygon{}
+	for _, span := range spans {
+		// split again so that no part is 180 degrees or wider
+		parts := int(math.Ceil((span[1] - span[0]) / 179))
+		width := (span[1] - span[0]) / float64(parts)
+		for i := 0; i < parts; i++ {
+			west := span[0] + float64(i)*width
+			polygons = append(polygons, polygon{boxRing(west, bbox.MinLat, west+width, bbox.MaxLat)})
+		}
+	}
+
+	if len(polygons) == 1 {
+		return Geometry{Type: "Polygon", Coordinates: polygons[0]}
+	}
+	return Geometry{Type: "MultiPolygon", Coordinates: polygons}
+}
+
+// boxRing builds a counter-clockwise ring with extra points along the parallels.
+func boxRing(west, south, east, north float64) ring {
+	steps := int(math.Max(1, math.Ceil((east-west)/bboxEdgeStepDeg)))
+	result := ring{}
+	for i := 0; i <= steps; i++ {
+		result = append(result, []float64{west + (east-west)*float64(i)/float64(steps), south})
+	}
+	for i := steps; i >= 0; i-- {
+		result = append(result, []float64{west + (east-west)*float64(i)/float64(steps), north})
+	}
+	return append(result, []float64{west, south})
+}
+
+// ParseGeometry parses and validates a GeoJSON Polygon or MultiPolygon, or a Feature wrapping one.
+// Rings must be closed and must not cross the antimeridian (RFC 7946 asks for such shapes to be
+// split into a MultiPolygon). Rings with the wrong winding order are reoriented to the right-hand rule:
+// exterior rings counter-clockwise and holes clockwise.
+func ParseGeometry(data []byte) (Geometry, error) {
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Geometry{}, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+
+	switch raw.Type {
+	case "Feature":
+		if len(raw.Geometry) == 0 {
+			return Geometry{}, errors.New("GeoJSON Feature has no geometry")
+		}
+		return ParseGeometry(raw.Geometry)
+
+	case "Polygon":
+		var coordinates polygon
+		if err := json.Unmarshal(raw.Coordinates, &coordinates); err != nil {
+			return Geometry{}, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		if err := normalizePolygon(coordinates); err != nil {
+			return Geometry{}, err
+		}
+		return Geometry{Type: "Polygon", Coordinates: coordinates}, nil
+
+	case "MultiPolygon":
+		var coordinates []polygon
+		if err := json.Unmarshal(raw.Coordinates, &coordinates); err != nil {
+			return Geometry{}, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+		if len(coordinates) == 0 {
+			return Geometry{}, errors.New("MultiPolygon has no polygons")
+		}
+		for i := range coordinates {
+			if err := normalizePolygon(coordinates[i]); err != nil {
+				return Geometry{}, fmt.Errorf("polygon %d: %w", i, err)
+			}
+		}
+		return Geometry{Type: "MultiPolygon", Coordinates: coordinates}, nil
+
+	default:
+		return Geometry{}, fmt.Errorf("unsupported GeoJSON type %q, expected Polygon or MultiPolygon", raw.Type)
+	}
+}
+
+func normalizePolygon(rings polygon) error {
+	if len(rings) == 0 {
+		return errors.New("polygon has no rings")
+	}
+
+	for i, currentRing := range rings {
+		if len(currentRing) < 4 {
+			return fmt.Errorf("ring %d must have at least 4 positions", i)
+		}
+		for _, position := range currentRing {
+			if len(position) < 2 {
+				return fmt.Errorf("ring %d has a position without longitude and latitude", i)
+			}
+			if err := validatePosition(position[0], position[1]); err != nil {
+				return fmt.Errorf("ring %d: %w", i, err)
+			}
+		}
+
+		first, last := currentRing[0], currentRing[len(currentRing)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("ring %d is not closed, the first and last positions must be equal", i)
+		}
+
+		for j := 1; j < len(currentRing); j++ {
+			if math.Abs(currentRing[j][0]-currentRing[j-1][0]) > 180 {
+				return fmt.Errorf("ring %d crosses the antimeridian, split it into a MultiPolygon at longitude 180", i)
+			}
+		}
+
+		// exterior ring (i == 0) must be counter-clockwise, holes clockwise
+		isCounterClockwise := signedArea(currentRing) > 0
+		if isCounterClockwise != (i == 0) {
+			reverseRing(currentRing)
+		}
+	}
+	return nil
+}
+
+// signedArea is the planar shoelace area, positive for counter-clockwise rings.
+func signedArea(currentRing ring) float64 {
+	area := 0.0
+	for i := 0; i < len(currentRing)-1; i++ {
+		area += currentRing[i][0]*currentRing[i+1][1] - currentRing[i+1][0]*currentRing[i][1]
+	}
+	return area / 2
+}
+
+func reverseRing(currentRing ring) {
+	for i, j := 0, len(currentRing)-1; i < j; i, j = i+1, j-1 {
+		currentRing[i], currentRing[j] = currentRing[j], currentRing[i]
+	}
+}
+
+func validatePosition(longitude float64, latitude float64) error {
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude %v is out of range [-180, 180]", longitude)
+	}
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude %v is out of range [-90, 90]", latitude)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "sync"
+
+// KeyedMutex is a set of mutexes by key, so work on one key never waits for work on another.
+// The mutex of a key is only kept while it is held or waited for.
+type KeyedMutex[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int // Holders and waiters of the lock
+}
+
+// Lock locks the mutex of the key and returns the function unlocking it.
+func (keyed *KeyedMutex[K]) Lock(key K) func() {
+	keyed.mu.Lock()
+	if keyed.locks == nil {
+		keyed.locks = map[K]*keyedLock{}
+	}
+	lock, ok := keyed.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		keyed.locks[key] = lock
+	}
+	lock.refs++
+	keyed.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		keyed.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(keyed.locks, key)
+		}
+		keyed.mu.Unlock()
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// keywordStopWords are frequent headline words which say nothing about a reader's interests.
+var keywordStopWords = map[string]bool{
+	"about": true, "after": true, "against": true, "amid": true, "also": true, "been": true, "before": true,
+	"being": true, "between": true, "could": true, "during": true, "from": true, "have": true, "here": true,
+	"into": true, "just": true, "more": true, "news": true, "over": true, "says": true, "said": true,
+	"some": true, "than": true, "that": true, "their": true, "them": true, "they": true, "this": true,
+	"today": true, "under": true, "were": true, "what": true, "when": true, "which": true, "while": true,
+	"will": true, "with": true, "would": true, "year": true, "your": true, "latest": true, "update": true,
+}
+
+// ExtractKeywords returns the distinct lowercased words of at least 4 letters in the text, without stop words.
+func ExtractKeywords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := map[string]bool{}
+	keywords := []string{}
+	for _, word := range words {
+		if len([]rune(word)) < 4 || keywordStopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"math"
+
 	"github.com/codingsince1985/geo-golang/openstreetmap"
 )
 
@@ -12,4 +14,17 @@ func ExtractLatAndLon(location string) (float64, float64, error) {
 		return 0, 0, err
 	}
 	return latAndLon.Lat, latAndLon.Lng, nil
-}
\ No newline at end of file
+}
+
+// EarthRadiusKm is the mean radius of the earth used for great-circle distances.
+const EarthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two coordinates.
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codingsince1985/geo-golang/openstreetmap"
+)
+
+const (
+	REVERSE_GEOCODER_OFFLINE       = "offline"
+	REVERSE_GEOCODER_OPENSTREETMAP = "openstreetmap"
+)
+
+var ErrPlaceNotFound = errors.New("no place found for the given coordinates")
+
+// Place is the result of reverse geocoding a coordinate.
+type Place struct {
+	City        string `json:"city"`
+	Region      string `json:"region"`
+	CountryCode string `json:"country_code"`
+}
+
+// DisplayName joins the known parts of the place, e.g. "Hazaribagh, Jharkhand, IN".
+func (place Place) DisplayName() string {
+	parts := []string{}
+	for _, part := range []string{place.City, place.Region, place.CountryCode} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ReverseGeocoder resolves a coordinate into a city, region and ISO country code.
+type ReverseGeocoder interface {
+	ReverseGeocode(latitude float64, longitude float64) (Place, error)
+}
+
+// NewReverseGeocoder returns the reverse geocoder configured by name.
+// The offline gazetteer is used unless "openstreetmap" is asked for explicitly.
+func NewReverseGeocoder(name string, gazetteerPath string, maxDistanceKm float64) (ReverseGeocoder, error) {
+	switch strings.ToLower(name) {
+	case REVERSE_GEOCODER_OPENSTREETMAP:
+		return OpenStreetMapReverseGeocoder{}, nil
+	case "", REVERSE_GEOCODER_OFFLINE:
+		return LoadGazetteer(gazetteerPath, maxDistanceKm)
+	default:
+		return nil, fmt.Errorf("unknown reverse geocoder %q", name)
+	}
+}
+
+// GazetteerEntry is a single populated place in the offline gazetteer file.
+type GazetteerEntry struct {
+	Name        string  `json:"name"`
+	Region      string  `json:"region"`
+	CountryCode string  `json:"country_code"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+// Gazetteer is an offline reverse geocoder which resolves a coordinate
+// to the nearest known place within MaxDistanceKm.
+type Gazetteer struct {
+	Entries       []GazetteerEntry
+	MaxDistanceKm float64
+}
+
+func LoadGazetteer(path string, maxDistanceKm float64) (*Gazetteer, error) {
+	dataByte, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []GazetteerEntry
+	if err := json.Unmarshal(dataByte, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("gazetteer %s has no entries", path)
+	}
+
+	return &Gazetteer{
+		Entries:       entries,
+		MaxDistanceKm: maxDistanceKm,
+	}, nil
+}
+
+func (gazetteer *Gazetteer) ReverseGeocode(latitude float64, longitude float64) (Place, error) {
+	nearest := -1
+	nearestDistance := 0.0
+	for i, entry := range gazetteer.Entries {
+		distance := HaversineKm(latitude, longitude, entry.Latitude, entry.Longitude)
+		if nearest == -1 || distance < nearestDistance {
+			nearest, nearestDistance = i, distance
+		}
+	}
+
+	if nearest == -1 || (gazetteer.MaxDistanceKm > 0 && nearestDistance > gazetteer.MaxDistanceKm) {
+		return Place{}, ErrPlaceNotFound
+	}
+
+	entry := gazetteer.Entries[nearest]
+	return Place{
+		City:        entry.Name,
+		Region:      entry.Region,
+		CountryCode: entry.CountryCode,
+	}, nil
+}
+
+// OpenStreetMapReverseGeocoder uses the public Nominatim API.
+// It is rate limited, so prefer the offline gazetteer for bulk backfills.
+type OpenStreetMapReverseGeocoder struct{}
+
+func (OpenStreetMapReverseGeocoder) ReverseGeocode(latitude float64, longitude float64) (Place, error) {
+	address, err := openstreetmap.Geocoder().ReverseGeocode(latitude, longitude)
+	if err != nil {
+		return Place{}, err
+	}
+	if address == nil {
+		return Place{}, ErrPlaceNotFound
+	}
+
+	city := address.City
+	if city == "" {
+		city = address.County
+	}
+	return Place{
+		City:        city,
+		Region:      address.State,
+		CountryCode: strings.ToUpper(address.CountryCode),
+	}, nil
+}
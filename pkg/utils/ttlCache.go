@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLCache is a concurrency-safe in-memory cache whose entries expire 'ttl' after they are set.
+// Expired entries are removed lazily, on Get and whenever the cache grows past maxEntries.
+type TTLCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[K]ttlCacheEntry[V]
+}
+
+type ttlCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+func NewTTLCache[K comparable, V any](ttl time.Duration, maxEntries int) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[K]ttlCacheEntry[V]{},
+	}
+}
+
+func (cache *TTLCache[K, V]) Get(key K) (V, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(cache.entries, key)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (cache *TTLCache[K, V]) Set(key K, value V) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	if cache.maxEntries > 0 && len(cache.entries) >= cache.maxEntries {
+		for entryKey, entry := range cache.entries {
+			if now.After(entry.expiresAt) {
+				delete(cache.entries, entryKey)
+			}
+		}
+		// Still full, evict arbitrary entries
+		for entryKey := range cache.entries {
+			if len(cache.entries) < cache.maxEntries {
+				break
+			}
+			delete(cache.entries, entryKey)
+		}
+	}
+	cache.entries[key] = ttlCacheEntry[V]{value: value, expiresAt: now.Add(cache.ttl)}
+}
+
+func (cache *TTLCache[K, V]) Delete(key K) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.entries, key)
+}
@@ -8,13 +8,15 @@ import (
 
 	// "github.com/shivam-cse/contextual-news-api/internal/models"
 	"github.com/shivam-cse/contextual-news-api/pkg/startup"
+	"github.com/shivam-cse/contextual-news-api/pkg/utils"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
 const FILE = "../data/news_data.json"
+const GAZETTEER_FILE = "../data/gazetteer.json"
 
-func helper(database *mongo.Database) error {
+func helper(database *mongo.Database, geocoder utils.ReverseGeocoder) error {
 	// Initialize the MongoDB with the JSON data
 	collection := database.Collection("news")
 
@@ -52,6 +54,15 @@ func helper(database *mongo.Database) error {
 						"type":        "Point",
 						"coordinates": []float64{long, lat},
 					}
+
+					// Enrich the article with city, region and country code
+					place, err := geocoder.ReverseGeocode(lat, long)
+					if err == nil {
+						articleMap["city"] = place.City
+						articleMap["region"] = place.Region
+						articleMap["country_code"] = place.CountryCode
+						articleMap["place_name"] = place.DisplayName()
+					}
 				}
 			}
 		}
@@ -86,9 +97,19 @@ func UploadJSON() {
 	// Get a handle to the specific database we want to use.
 	database := mongoClient.Database(config.MongoDatabase)
 
+	// The script runs from scripts/, so the default gazetteer path is relative to it
+	gazetteerPath := config.GazetteerPath
+	if os.Getenv("GAZETTEER_PATH") == "" {
+		gazetteerPath = GAZETTEER_FILE
+	}
+	geocoder, err := utils.NewReverseGeocoder(config.ReverseGeocoder, gazetteerPath, config.GazetteerMaxDistanceKm)
+	if err != nil {
+		panic("Error creating reverse geocoder: " + err.Error())
+	}
+
 	log.Println("Initializing MongoDB with JSON data")
 	// Initialize the MongoDB with the JSON data
-	err = helper(database)
+	err = helper(database, geocoder)
 	if err != nil {
 		panic("Failed to upload news json data: " + err.Error())
 	}